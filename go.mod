@@ -2,4 +2,11 @@ module github.com/jodok/nocli
 
 go 1.22.0
 
-require github.com/alecthomas/kong v1.9.0
+require (
+	filippo.io/age v1.2.1
+	github.com/alecthomas/kong v1.9.0
+	golang.org/x/crypto v0.24.0
+	golang.org/x/term v0.21.0
+)
+
+require golang.org/x/sys v0.21.0 // indirect
@@ -0,0 +1,270 @@
+// Package archive reads and writes nocli backup archives: a directory of raw
+// record values (records/<table>/<id>.json) described by manifest.json.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	FormatName    = "nocli-backup"
+	FormatVersion = 1
+
+	manifestFile = "manifest.json"
+	recordsDir   = "records"
+)
+
+type Manifest struct {
+	Format      string `json:"format"`
+	Version     int    `json:"version"`
+	SpaceID     string `json:"space_id,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	Complete    bool   `json:"complete"`
+	// Base is the archive an incremental backup was taken against. Records
+	// with InBase set are stored there (or in its own base) instead of here.
+	Base    string                            `json:"base,omitempty"`
+	Roots   []string                          `json:"roots"`
+	Records map[string]map[string]RecordEntry `json:"records"`
+	Missing []string                          `json:"missing,omitempty"`
+}
+
+type RecordEntry struct {
+	Version int64 `json:"version"`
+	InBase  bool  `json:"in_base,omitempty"`
+}
+
+type Archive struct {
+	dir      string
+	manifest *Manifest
+	base     *Archive
+}
+
+// Create starts a new archive in dir, which must not already hold one.
+func Create(dir string, spaceID string, base string) (*Archive, error) {
+	if _, err := os.Stat(filepath.Join(dir, manifestFile)); err == nil {
+		return nil, fmt.Errorf("archive already exists in %s", dir)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, recordsDir), 0o700); err != nil {
+		return nil, fmt.Errorf("create archive dir: %w", err)
+	}
+
+	a := &Archive{dir: dir, manifest: &Manifest{
+		Format:    FormatName,
+		Version:   FormatVersion,
+		SpaceID:   spaceID,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Roots:     []string{},
+		Records:   map[string]map[string]RecordEntry{},
+	}}
+	if base != "" {
+		abs, err := filepath.Abs(base)
+		if err != nil {
+			return nil, fmt.Errorf("resolve base archive path: %w", err)
+		}
+		a.manifest.Base = abs
+		if a.base, err = Open(abs); err != nil {
+			return nil, fmt.Errorf("open base archive: %w", err)
+		}
+	}
+	return a, a.SaveManifest()
+}
+
+// Open loads an existing archive and, for incremental archives, its bases.
+func Open(dir string) (*Archive, error) {
+	b, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("read archive manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("parse archive manifest: %w", err)
+	}
+	if m.Format != FormatName {
+		return nil, fmt.Errorf("%s is not a nocli backup archive", dir)
+	}
+	if m.Version > FormatVersion {
+		return nil, fmt.Errorf("archive format version %d is newer than supported version %d", m.Version, FormatVersion)
+	}
+	if m.Records == nil {
+		m.Records = map[string]map[string]RecordEntry{}
+	}
+
+	a := &Archive{dir: dir, manifest: &m}
+	if m.Base != "" {
+		if a.base, err = Open(m.Base); err != nil {
+			return nil, fmt.Errorf("open base archive: %w", err)
+		}
+	}
+	return a, nil
+}
+
+func (a *Archive) Dir() string {
+	return a.dir
+}
+
+func (a *Archive) Manifest() *Manifest {
+	return a.manifest
+}
+
+// Base returns the archive this one is incremental against, if any.
+func (a *Archive) Base() *Archive {
+	return a.base
+}
+
+// Put stores a record value in this archive.
+func (a *Archive) Put(table string, id string, value map[string]any) error {
+	path, err := a.recordPath(table, id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create record dir: %w", err)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal %s/%s: %w", table, id, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("write %s/%s: %w", table, id, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit %s/%s: %w", table, id, err)
+	}
+	a.setEntry(table, id, RecordEntry{Version: RecordVersion(value)})
+	return nil
+}
+
+// Reuse records that a record is unchanged since the base archive.
+func (a *Archive) Reuse(table string, id string, version int64) {
+	a.setEntry(table, id, RecordEntry{Version: version, InBase: true})
+}
+
+func (a *Archive) setEntry(table string, id string, e RecordEntry) {
+	if a.manifest.Records[table] == nil {
+		a.manifest.Records[table] = map[string]RecordEntry{}
+	}
+	a.manifest.Records[table][id] = e
+}
+
+// Entry returns the manifest entry of a record in this archive.
+func (a *Archive) Entry(table string, id string) (RecordEntry, bool) {
+	e, ok := a.manifest.Records[table][id]
+	return e, ok
+}
+
+// Get returns a record value, following the base chain for reused records.
+func (a *Archive) Get(table string, id string) (map[string]any, bool, error) {
+	e, ok := a.Entry(table, id)
+	if ok && e.InBase {
+		if a.base == nil {
+			return nil, false, fmt.Errorf("record %s/%s is in a base archive that is not available", table, id)
+		}
+		return a.base.Get(table, id)
+	}
+
+	path, err := a.recordPath(table, id)
+	if err != nil {
+		return nil, false, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("read %s/%s: %w", table, id, err)
+	}
+	var value map[string]any
+	if err := json.Unmarshal(b, &value); err != nil {
+		return nil, false, fmt.Errorf("parse %s/%s: %w", table, id, err)
+	}
+	if !ok {
+		// Written by an interrupted run before the manifest was saved.
+		a.setEntry(table, id, RecordEntry{Version: RecordVersion(value)})
+	}
+	return value, true, nil
+}
+
+// Records returns all record values of a table, resolving reused records.
+func (a *Archive) Records(table string) (map[string]map[string]any, error) {
+	out := map[string]map[string]any{}
+	for id := range a.manifest.Records[table] {
+		v, ok, err := a.Get(table, id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out[id] = v
+		}
+	}
+	return out, nil
+}
+
+// Tables lists the tables that have at least one record.
+func (a *Archive) Tables() []string {
+	out := make([]string, 0, len(a.manifest.Records))
+	for table, rows := range a.manifest.Records {
+		if len(rows) > 0 {
+			out = append(out, table)
+		}
+	}
+	return out
+}
+
+// SaveManifest atomically rewrites manifest.json.
+func (a *Archive) SaveManifest() error {
+	b, err := json.MarshalIndent(a.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	b = append(b, '\n')
+	path := filepath.Join(a.dir, manifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit manifest: %w", err)
+	}
+	return nil
+}
+
+// Finish marks the archive complete and saves the manifest.
+func (a *Archive) Finish() error {
+	a.manifest.Complete = true
+	a.manifest.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	return a.SaveManifest()
+}
+
+func (a *Archive) recordPath(table string, id string) (string, error) {
+	if !safeName(table) || !safeName(id) {
+		return "", fmt.Errorf("invalid record reference %q/%q", table, id)
+	}
+	return filepath.Join(a.dir, recordsDir, table, id+".json"), nil
+}
+
+func safeName(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
+
+// RecordVersion returns a record value's version, or 0 when absent.
+func RecordVersion(value map[string]any) int64 {
+	switch v := value["version"].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+// Package browsercookies reads Notion cookies from local browser profiles on
+// Linux: Firefox's plaintext cookies.sqlite and Chromium's Cookies database
+// encrypted with the built-in "peanuts" key.
+package browsercookies
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Cookie is one cookie read from a browser store.
+type Cookie struct {
+	Host  string
+	Name  string
+	Value string
+}
+
+// Find returns the cookie database of a browser profile. When profileDir is
+// empty, the most recently modified default location is used.
+func Find(browser string, profileDir string) (string, error) {
+	var name string
+	var candidates []string
+	home, _ := os.UserHomeDir()
+
+	switch browser {
+	case "firefox":
+		name = "cookies.sqlite"
+		if profileDir == "" {
+			for _, root := range []string{
+				filepath.Join(home, ".mozilla", "firefox"),
+				filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+				filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"),
+			} {
+				m, _ := filepath.Glob(filepath.Join(root, "*", name))
+				candidates = append(candidates, m...)
+			}
+		}
+	case "chromium":
+		name = "Cookies"
+		if profileDir == "" {
+			for _, root := range []string{
+				filepath.Join(home, ".config", "chromium"),
+				filepath.Join(home, ".config", "google-chrome"),
+				filepath.Join(home, ".config", "BraveSoftware", "Brave-Browser"),
+				filepath.Join(home, ".config", "microsoft-edge"),
+				filepath.Join(home, "snap", "chromium", "common", "chromium"),
+			} {
+				for _, pattern := range []string{"*/Network/Cookies", "*/Cookies"} {
+					m, _ := filepath.Glob(filepath.Join(root, pattern))
+					candidates = append(candidates, m...)
+				}
+			}
+		}
+	default:
+		return "", fmt.Errorf("unsupported browser %q", browser)
+	}
+
+	if profileDir != "" {
+		for _, p := range []string{filepath.Join(profileDir, "Network", name), filepath.Join(profileDir, name), profileDir} {
+			if st, err := os.Stat(p); err == nil && !st.IsDir() {
+				return p, nil
+			}
+		}
+		return "", fmt.Errorf("no %s found in %s", name, profileDir)
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no %s cookie store found; pass --profile-dir", browser)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return modTime(candidates[i]) > modTime(candidates[j])
+	})
+	return candidates[0], nil
+}
+
+func modTime(path string) int64 {
+	latest := int64(0)
+	for _, p := range []string{path, path + "-wal"} {
+		if st, err := os.Stat(p); err == nil && st.ModTime().UnixNano() > latest {
+			latest = st.ModTime().UnixNano()
+		}
+	}
+	return latest
+}
+
+// Read returns the cookies for hosts matching domain (e.g. "notion.so").
+func Read(browser string, path string, domain string) ([]Cookie, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	switch browser {
+	case "firefox":
+		return readFirefox(db, domain)
+	case "chromium":
+		return readChromium(db, domain)
+	default:
+		return nil, fmt.Errorf("unsupported browser %q", browser)
+	}
+}
+
+func readFirefox(db *sqliteDB, domain string) ([]Cookie, error) {
+	rows, err := db.table("moz_cookies")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Cookie, 0)
+	for _, row := range rows {
+		host, _ := row["host"].(string)
+		if !hostMatches(host, domain) {
+			continue
+		}
+		name, _ := row["name"].(string)
+		value, _ := row["value"].(string)
+		out = append(out, Cookie{Host: host, Name: name, Value: value})
+	}
+	return out, nil
+}
+
+func readChromium(db *sqliteDB, domain string) ([]Cookie, error) {
+	rows, err := db.table("cookies")
+	if err != nil {
+		return nil, err
+	}
+	// Since database version 24 the plaintext is prefixed with
+	// SHA-256(host_key).
+	hashedValues := false
+	if meta, err := db.table("meta"); err == nil {
+		for _, row := range meta {
+			if k, _ := row["key"].(string); k == "version" {
+				v, _ := row["value"].(string)
+				n, _ := strconv.Atoi(v)
+				hashedValues = n >= 24
+			}
+		}
+	}
+
+	key := pbkdf2.Key([]byte("peanuts"), []byte("saltysalt"), 1, 16, sha1.New)
+	out := make([]Cookie, 0)
+	for _, row := range rows {
+		host, _ := row["host_key"].(string)
+		if !hostMatches(host, domain) {
+			continue
+		}
+		name, _ := row["name"].(string)
+		value, _ := row["value"].(string)
+		if enc, _ := row["encrypted_value"].([]byte); value == "" && len(enc) > 0 {
+			plain, err := decryptChromium(enc, key)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt cookie %s: %w", name, err)
+			}
+			if hashedValues && len(plain) >= 32 {
+				sum := sha256.Sum256([]byte(host))
+				if bytes.Equal(plain[:32], sum[:]) {
+					plain = plain[32:]
+				}
+			}
+			value = string(plain)
+		}
+		out = append(out, Cookie{Host: host, Name: name, Value: value})
+	}
+	return out, nil
+}
+
+func decryptChromium(enc []byte, key []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(enc, []byte("v10")):
+	case bytes.HasPrefix(enc, []byte("v11")):
+		return nil, fmt.Errorf("cookie is encrypted with a keyring-stored key (v11), which is not supported; use 'nocli auth import' instead")
+	default:
+		return nil, fmt.Errorf("unknown cookie encryption prefix %q", enc[:min(3, len(enc))])
+	}
+	data := enc[3:]
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext length %d is not a multiple of the block size", len(data))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := bytes.Repeat([]byte{' '}, aes.BlockSize)
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize || pad > len(plain) {
+		return nil, fmt.Errorf("bad padding (cookie encrypted with a different key?)")
+	}
+	return plain[:len(plain)-pad], nil
+}
+
+func hostMatches(host string, domain string) bool {
+	h := strings.TrimPrefix(strings.ToLower(host), ".")
+	d := strings.ToLower(domain)
+	return h == d || strings.HasSuffix(h, "."+d)
+}
@@ -0,0 +1,111 @@
+package browsercookies
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		browser string
+		path    string
+		want    []Cookie
+	}{
+		{
+			name:    "firefox with overflow page and wal",
+			browser: "firefox",
+			path:    filepath.Join("testdata", "firefox", "cookies.sqlite"),
+			want: []Cookie{
+				{Host: ".www.notion.so", Name: "token_v2", Value: "v02%3Auser_token"},
+				{Host: "www.notion.so", Name: "notion_user_id", Value: "aaaaaaaa-aaaa-4aaa-8aaa-aaaaaaaaaaaa"},
+				{Host: "notion.so", Name: "big", Value: strings.Repeat("x", 3000)},
+				{Host: "www.notion.so", Name: "wal_only", Value: "fresh"},
+			},
+		},
+		{
+			name:    "chromium v10 with host hash",
+			browser: "chromium",
+			path:    filepath.Join("testdata", "chromium", "Cookies"),
+			want: []Cookie{
+				{Host: ".www.notion.so", Name: "token_v2", Value: "v02%3Achromium_token"},
+				{Host: "www.notion.so", Name: "notion_user_id", Value: "bbbbbbbb-bbbb-4bbb-8bbb-bbbbbbbbbbbb"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(tt.browser, tt.path, "notion.so")
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteTableScansAllRows(t *testing.T) {
+	db, err := openSQLite(filepath.Join("testdata", "firefox", "cookies.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.table("moz_cookies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 200 filler rows spread over several leaf pages, 4 notion.so and
+	// notnotion.so rows, and one row that is only in the WAL.
+	if len(rows) != 205 {
+		t.Fatalf("got %d rows, want 205", len(rows))
+	}
+	if got := rows[0]["expiry"]; got != int64(1700000000) {
+		t.Errorf("expiry = %#v, want int64(1700000000)", got)
+	}
+	if _, err := db.table("missing"); err == nil {
+		t.Error("table(missing) succeeded, want error")
+	}
+}
+
+func TestDecryptChromiumErrors(t *testing.T) {
+	key := make([]byte, 16)
+	tests := []struct {
+		name string
+		enc  []byte
+		want string
+	}{
+		{"keyring v11", []byte("v11abcdefghijklmnop"), "v11"},
+		{"unknown prefix", []byte("xyz"), "unknown cookie encryption prefix"},
+		{"short ciphertext", []byte("v10abc"), "multiple of the block size"},
+		{"empty ciphertext", []byte("v10"), "multiple of the block size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decryptChromium(tt.enc, key)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("decryptChromium error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostMatches(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"notion.so", true},
+		{".notion.so", true},
+		{"www.Notion.so", true},
+		{"notnotion.so", false},
+		{"notion.so.evil.com", false},
+	}
+	for _, tt := range tests {
+		if got := hostMatches(tt.host, "notion.so"); got != tt.want {
+			t.Errorf("hostMatches(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package browsercookies
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// sqliteDB is a minimal read-only SQLite reader: enough to scan whole tables
+// of a browser cookie store, including pages still sitting in the -wal file.
+// It does not use indexes, and it ignores rows it cannot decode.
+type sqliteDB struct {
+	data     []byte
+	pageSize int
+	usable   int
+	walPages map[uint32][]byte
+}
+
+func openSQLite(path string) (*sqliteDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if len(data) < 100 || string(data[:16]) != "SQLite format 3\x00" {
+		return nil, fmt.Errorf("%s is not a SQLite database", path)
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	db := &sqliteDB{
+		data:     data,
+		pageSize: pageSize,
+		usable:   pageSize - int(data[20]),
+	}
+	if wal, err := os.ReadFile(path + "-wal"); err == nil {
+		db.walPages = parseWAL(wal, pageSize)
+	}
+	return db, nil
+}
+
+// parseWAL returns the newest committed copy of each page in a WAL file.
+func parseWAL(wal []byte, pageSize int) map[uint32][]byte {
+	const headerSize, frameHeaderSize = 32, 24
+	if len(wal) < headerSize {
+		return nil
+	}
+	magic := binary.BigEndian.Uint32(wal[0:4])
+	if magic != 0x377f0682 && magic != 0x377f0683 {
+		return nil
+	}
+	if int(binary.BigEndian.Uint32(wal[8:12])) != pageSize {
+		return nil
+	}
+	salt1 := binary.BigEndian.Uint32(wal[16:20])
+	salt2 := binary.BigEndian.Uint32(wal[20:24])
+
+	committed := map[uint32][]byte{}
+	pending := map[uint32][]byte{}
+	for off := headerSize; off+frameHeaderSize+pageSize <= len(wal); off += frameHeaderSize + pageSize {
+		fh := wal[off : off+frameHeaderSize]
+		if binary.BigEndian.Uint32(fh[8:12]) != salt1 || binary.BigEndian.Uint32(fh[12:16]) != salt2 {
+			break
+		}
+		pageNo := binary.BigEndian.Uint32(fh[0:4])
+		pending[pageNo] = wal[off+frameHeaderSize : off+frameHeaderSize+pageSize]
+		if binary.BigEndian.Uint32(fh[4:8]) != 0 {
+			for k, v := range pending {
+				committed[k] = v
+			}
+			pending = map[uint32][]byte{}
+		}
+	}
+	return committed
+}
+
+func (db *sqliteDB) page(n uint32) ([]byte, error) {
+	if p, ok := db.walPages[n]; ok {
+		return p, nil
+	}
+	start := int(n-1) * db.pageSize
+	if n == 0 || start+db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("sqlite page %d out of range", n)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// table returns every row of a table as column name -> value.
+func (db *sqliteDB) table(name string) ([]map[string]any, error) {
+	var root uint32
+	var columns []string
+	err := db.scan(1, func(rowid int64, values []any) error {
+		if len(values) < 5 {
+			return nil
+		}
+		typ, _ := values[0].(string)
+		tblName, _ := values[1].(string)
+		if typ != "table" || !strings.EqualFold(tblName, name) {
+			return nil
+		}
+		rp, _ := values[3].(int64)
+		sql, _ := values[4].(string)
+		root = uint32(rp)
+		columns = parseCreateTableColumns(sql)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if root == 0 {
+		return nil, fmt.Errorf("table %s not found", name)
+	}
+
+	rows := make([]map[string]any, 0)
+	err = db.scan(root, func(rowid int64, values []any) error {
+		row := make(map[string]any, len(columns))
+		for i, col := range columns {
+			if i < len(values) {
+				row[col] = values[i]
+			}
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+// scan walks a table b-tree in rowid order.
+func (db *sqliteDB) scan(pageNo uint32, fn func(rowid int64, values []any) error) error {
+	return db.scanDepth(pageNo, fn, 0)
+}
+
+func (db *sqliteDB) scanDepth(pageNo uint32, fn func(rowid int64, values []any) error, depth int) error {
+	if depth > 64 {
+		return fmt.Errorf("sqlite b-tree too deep (corrupt database?)")
+	}
+	page, err := db.page(pageNo)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if pageNo == 1 {
+		hdr = 100
+	}
+	if hdr+8 > len(page) {
+		return fmt.Errorf("sqlite page %d truncated", pageNo)
+	}
+	kind := page[hdr]
+	cells := int(binary.BigEndian.Uint16(page[hdr+3 : hdr+5]))
+	ptrs := hdr + 8
+	if kind == 0x05 {
+		ptrs = hdr + 12
+	}
+	if ptrs+2*cells > len(page) {
+		return fmt.Errorf("sqlite page %d: %d cell pointers do not fit the page (corrupt database?)", pageNo, cells)
+	}
+	cell := func(i int) (int, error) {
+		off := int(binary.BigEndian.Uint16(page[ptrs+2*i:]))
+		if off >= len(page) {
+			return 0, fmt.Errorf("sqlite page %d: cell %d offset %d out of range (corrupt database?)", pageNo, i, off)
+		}
+		return off, nil
+	}
+
+	switch kind {
+	case 0x05: // interior table page
+		for i := 0; i < cells; i++ {
+			off, err := cell(i)
+			if err != nil {
+				return err
+			}
+			if off+4 > len(page) {
+				return fmt.Errorf("sqlite page %d: cell %d truncated (corrupt database?)", pageNo, i)
+			}
+			if err := db.scanDepth(binary.BigEndian.Uint32(page[off:off+4]), fn, depth+1); err != nil {
+				return err
+			}
+		}
+		return db.scanDepth(binary.BigEndian.Uint32(page[hdr+8:hdr+12]), fn, depth+1)
+	case 0x0d: // leaf table page
+		for i := 0; i < cells; i++ {
+			off, err := cell(i)
+			if err != nil {
+				return err
+			}
+			rowid, payload, err := db.leafCell(page, off)
+			if err != nil {
+				continue
+			}
+			values, err := decodeRecord(payload)
+			if err != nil {
+				continue
+			}
+			if err := fn(rowid, values); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("sqlite page %d is not a table b-tree page (type %#x)", pageNo, kind)
+	}
+}
+
+func (db *sqliteDB) leafCell(page []byte, off int) (int64, []byte, error) {
+	if off >= len(page) {
+		return 0, nil, fmt.Errorf("cell offset out of range")
+	}
+	size, n := readVarint(page[off:])
+	off += n
+	rowid, n := readVarint(page[off:])
+	off += n
+
+	total := int(size)
+	maxLocal := db.usable - 35
+	local := total
+	if total > maxLocal {
+		minLocal := (db.usable-12)*32/255 - 23
+		local = minLocal + (total-minLocal)%(db.usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if local < 0 || off+local > len(page) {
+		return 0, nil, fmt.Errorf("cell payload out of range")
+	}
+	payload := make([]byte, 0, total)
+	payload = append(payload, page[off:off+local]...)
+	if local == total {
+		return int64(rowid), payload, nil
+	}
+
+	if off+local+4 > len(page) {
+		return 0, nil, fmt.Errorf("overflow pointer out of range")
+	}
+	next := binary.BigEndian.Uint32(page[off+local:])
+	for next != 0 && len(payload) < total {
+		ov, err := db.page(next)
+		if err != nil {
+			return 0, nil, err
+		}
+		chunk := min(total-len(payload), db.usable-4)
+		payload = append(payload, ov[4:4+chunk]...)
+		next = binary.BigEndian.Uint32(ov[0:4])
+	}
+	return int64(rowid), payload, nil
+}
+
+func decodeRecord(payload []byte) ([]any, error) {
+	headerSize, n := readVarint(payload)
+	if int(headerSize) > len(payload) || n == 0 {
+		return nil, fmt.Errorf("record header out of range")
+	}
+	types := make([]uint64, 0, 16)
+	for pos := n; pos < int(headerSize); {
+		t, m := readVarint(payload[pos:])
+		if m == 0 {
+			return nil, fmt.Errorf("bad serial type")
+		}
+		types = append(types, t)
+		pos += m
+	}
+
+	values := make([]any, 0, len(types))
+	body := payload[headerSize:]
+	for _, t := range types {
+		var size int
+		switch {
+		case t == 0, t == 8, t == 9:
+			size = 0
+		case t >= 1 && t <= 4:
+			size = int(t)
+		case t == 5:
+			size = 6
+		case t == 6, t == 7:
+			size = 8
+		case t >= 12 && t%2 == 0:
+			size = int(t-12) / 2
+		case t >= 13:
+			size = int(t-13) / 2
+		default:
+			return nil, fmt.Errorf("unsupported serial type %d", t)
+		}
+		if size > len(body) {
+			return nil, fmt.Errorf("record body out of range")
+		}
+		v := body[:size]
+		body = body[size:]
+
+		switch {
+		case t == 0:
+			values = append(values, nil)
+		case t == 8:
+			values = append(values, int64(0))
+		case t == 9:
+			values = append(values, int64(1))
+		case t <= 6:
+			var x int64
+			for _, b := range v {
+				x = x<<8 | int64(b)
+			}
+			// Sign-extend from the stored width.
+			shift := uint(64 - 8*size)
+			values = append(values, x<<shift>>shift)
+		case t == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(v)))
+		case t%2 == 0:
+			values = append(values, append([]byte(nil), v...))
+		default:
+			values = append(values, string(v))
+		}
+	}
+	return values, nil
+}
+
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// parseCreateTableColumns returns column names in declaration order from a
+// CREATE TABLE statement.
+func parseCreateTableColumns(sql string) []string {
+	start := strings.Index(sql, "(")
+	end := strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil
+	}
+	body := sql[start+1 : end]
+
+	parts := make([]string, 0)
+	depth, last := 0, 0
+	for i, r := range body {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, body[last:i])
+				last = i + 1
+			}
+		}
+	}
+	parts = append(parts, body[last:])
+
+	cols := make([]string, 0, len(parts))
+	for _, p := range parts {
+		fields := strings.Fields(strings.TrimSpace(p))
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PRIMARY", "UNIQUE", "CONSTRAINT", "CHECK", "FOREIGN":
+			continue
+		}
+		cols = append(cols, strings.Trim(fields[0], "\"`[]'"))
+	}
+	return cols
+}
@@ -0,0 +1,150 @@
+package browsercookies
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadVarint(t *testing.T) {
+	tests := []struct {
+		in    []byte
+		want  uint64
+		wantN int
+	}{
+		{[]byte{0x00}, 0, 1},
+		{[]byte{0x7f}, 127, 1},
+		{[]byte{0x81, 0x00}, 128, 2},
+		{[]byte{0x82, 0x2c}, 300, 2},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 1<<64 - 1, 9},
+		{[]byte{0x81}, 0, 0},
+		{nil, 0, 0},
+	}
+	for _, tt := range tests {
+		got, n := readVarint(tt.in)
+		if got != tt.want || n != tt.wantN {
+			t.Errorf("readVarint(% x) = %d, %d; want %d, %d", tt.in, got, n, tt.want, tt.wantN)
+		}
+	}
+}
+
+func TestDecodeRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		want    []any
+	}{
+		{
+			name: "null, constants and text",
+			// header: size 5, NULL, 0, 1, text of length 2
+			payload: []byte{5, 0, 8, 9, 17, 'h', 'i'},
+			want:    []any{nil, int64(0), int64(1), "hi"},
+		},
+		{
+			name:    "negative one-byte and two-byte integers",
+			payload: []byte{3, 1, 2, 0xff, 0x01, 0x00},
+			want:    []any{int64(-1), int64(256)},
+		},
+		{
+			name:    "float and blob",
+			payload: []byte{3, 7, 16, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0, 0xca, 0xfe},
+			want:    []any{1.5, []byte{0xca, 0xfe}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeRecord(tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeRecord = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := decodeRecord([]byte{2, 21, 'x'}); err == nil {
+		t.Error("decodeRecord with truncated body succeeded, want error")
+	}
+}
+
+func TestParseCreateTableColumns(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{
+			"CREATE TABLE meta(key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY, value LONGVARCHAR)",
+			[]string{"key", "value"},
+		},
+		{
+			"CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, \"name\" TEXT, value TEXT DEFAULT (lower('X')), CONSTRAINT u UNIQUE (name, value))",
+			[]string{"id", "name", "value"},
+		},
+		{
+			"CREATE TABLE t([a b] INT, `c` TEXT, PRIMARY KEY (a))",
+			[]string{"a", "c"},
+		},
+		{"not sql", nil},
+	}
+	for _, tt := range tests {
+		if got := parseCreateTableColumns(tt.sql); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCreateTableColumns(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteCorruptPages(t *testing.T) {
+	orig, err := os.ReadFile(filepath.Join("testdata", "firefox", "cookies.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageSize := int(binary.BigEndian.Uint16(orig[16:18]))
+	tests := []struct {
+		name    string
+		corrupt func(b []byte) []byte
+	}{
+		{"schema cell count", func(b []byte) []byte {
+			binary.BigEndian.PutUint16(b[103:], 0xffff)
+			return b
+		}},
+		{"schema cell offset", func(b []byte) []byte {
+			binary.BigEndian.PutUint16(b[108:], 0xfff0)
+			return b
+		}},
+		{"table cell counts", func(b []byte) []byte {
+			for off := pageSize; off+8 <= len(b); off += pageSize {
+				binary.BigEndian.PutUint16(b[off+3:], 0xffff)
+			}
+			return b
+		}},
+		{"table cell offsets", func(b []byte) []byte {
+			for off := pageSize; off+12 <= len(b); off += pageSize {
+				binary.BigEndian.PutUint16(b[off+8:], 0xffff)
+				binary.BigEndian.PutUint16(b[off+12:], 0xffff)
+			}
+			return b
+		}},
+		{"truncated", func(b []byte) []byte {
+			return b[:pageSize+pageSize/2]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cookies.sqlite")
+			data := tt.corrupt(append([]byte(nil), orig...))
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			db, err := openSQLite(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.table("moz_cookies"); err == nil {
+				t.Error("table succeeded on a corrupt database, want error")
+			}
+		})
+	}
+}
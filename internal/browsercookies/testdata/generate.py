@@ -0,0 +1,75 @@
+#!/usr/bin/env python3
+"""Regenerates the cookie store fixtures used by the browsercookies tests.
+
+firefox/cookies.sqlite uses 1 KiB pages and enough rows for an interior
+b-tree page, one value that spills onto overflow pages, and one cookie that
+only exists in the -wal file. chromium/Cookies is a version 24 store with a
+v10-encrypted, host-hash-prefixed token_v2.
+"""
+import hashlib
+import os
+import shutil
+import sqlite3
+import subprocess
+import tempfile
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+
+
+def firefox():
+    out = os.path.join(HERE, "firefox")
+    os.makedirs(out, exist_ok=True)
+    tmp = tempfile.mkdtemp()
+    path = os.path.join(tmp, "cookies.sqlite")
+    db = sqlite3.connect(path)
+    db.execute("PRAGMA page_size = 1024")
+    db.execute("CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '', name TEXT, value TEXT, host TEXT, path TEXT, expiry INTEGER, CONSTRAINT moz_uniqueid UNIQUE (name, host, path, originAttributes))")
+    for i in range(200):
+        db.execute("INSERT INTO moz_cookies (name, value, host, path, expiry) VALUES (?, ?, ?, '/', ?)", ("c%d" % i, "v" * 40, "example%d.com" % i, 1700000000 + i))
+    db.execute("INSERT INTO moz_cookies (name, value, host, path, expiry) VALUES ('token_v2', 'v02%3Auser_token', '.www.notion.so', '/', -1)")
+    db.execute("INSERT INTO moz_cookies (name, value, host, path, expiry) VALUES ('notion_user_id', 'aaaaaaaa-aaaa-4aaa-8aaa-aaaaaaaaaaaa', 'www.notion.so', '/', 0)")
+    db.execute("INSERT INTO moz_cookies (name, value, host, path, expiry) VALUES ('big', ?, 'notion.so', '/', 0)", ("x" * 3000,))
+    db.execute("INSERT INTO moz_cookies (name, value, host, path, expiry) VALUES ('other', 'no', 'notnotion.so', '/', 0)")
+    db.commit()
+    db.execute("PRAGMA journal_mode = WAL")
+    db.execute("PRAGMA wal_autocheckpoint = 0")
+    db.execute("INSERT INTO moz_cookies (name, value, host, path, expiry) VALUES ('wal_only', 'fresh', 'www.notion.so', '/', 0)")
+    db.commit()
+    # Copy while the connection is open: closing checkpoints the WAL away.
+    shutil.copy(path, os.path.join(out, "cookies.sqlite"))
+    shutil.copy(path + "-wal", os.path.join(out, "cookies.sqlite-wal"))
+    db.close()
+    shutil.rmtree(tmp)
+
+
+def encrypt_v10(plain):
+    key = hashlib.pbkdf2_hmac("sha1", b"peanuts", b"saltysalt", 1, 16)
+    enc = subprocess.run(
+        ["openssl", "enc", "-aes-128-cbc", "-K", key.hex(), "-iv", "20" * 16],
+        input=plain, capture_output=True, check=True,
+    ).stdout
+    return b"v10" + enc
+
+
+def chromium():
+    out = os.path.join(HERE, "chromium")
+    os.makedirs(out, exist_ok=True)
+    path = os.path.join(out, "Cookies")
+    if os.path.exists(path):
+        os.remove(path)
+    db = sqlite3.connect(path)
+    db.execute("CREATE TABLE meta(key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY, value LONGVARCHAR)")
+    db.execute("INSERT INTO meta VALUES ('version', '24')")
+    db.execute("CREATE TABLE cookies(creation_utc INTEGER NOT NULL, host_key TEXT NOT NULL, top_frame_site_key TEXT NOT NULL, name TEXT NOT NULL, value TEXT NOT NULL, encrypted_value BLOB NOT NULL, path TEXT NOT NULL, UNIQUE (host_key, top_frame_site_key, name, path))")
+    host = ".www.notion.so"
+    token = encrypt_v10(hashlib.sha256(host.encode()).digest() + b"v02%3Achromium_token")
+    db.execute("INSERT INTO cookies VALUES (1, ?, '', 'token_v2', '', ?, '/')", (host, token))
+    db.execute("INSERT INTO cookies VALUES (2, 'www.notion.so', '', 'notion_user_id', 'bbbbbbbb-bbbb-4bbb-8bbb-bbbbbbbbbbbb', X'', '/')")
+    db.execute("INSERT INTO cookies VALUES (3, '.example.com', '', 'sid', '', ?, '/')", (encrypt_v10(b"ignored"),))
+    db.commit()
+    db.close()
+
+
+if __name__ == "__main__":
+    firefox()
+    chromium()
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type APICmd struct {
+	Endpoint string `arg:"" name:"endpoint" help:"Endpoint under /api/v3/ (e.g. getSpaces or /api/v3/getSpaces)"`
+	Data     string `name:"data" short:"d" default:"{}" help:"JSON payload: inline JSON, @file, or - for stdin"`
+	Flatten  bool   `name:"flatten" help:"Print the response recordMap as table -> id -> value"`
+	Output   string `name:"output" short:"o" help:"Write JSON output to this file instead of stdout"`
+}
+
+func (c *APICmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	raw, err := readPayload(c.Data)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(raw) {
+		return fmt.Errorf("--data is not valid JSON")
+	}
+
+	resp, err := client.Post(ctx, c.Endpoint, json.RawMessage(raw))
+	if err != nil {
+		return err
+	}
+	if c.Flatten {
+		if _, ok := resp["recordMap"].(map[string]any); !ok {
+			return fmt.Errorf("--flatten: response has no recordMap")
+		}
+		return writeOutput(ctx, c.Output, notionclient.FlattenRecordMap(resp))
+	}
+	return writeOutput(ctx, c.Output, resp)
+}
+
+func readPayload(data string) ([]byte, error) {
+	switch {
+	case data == "-":
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read payload from stdin: %w", err)
+		}
+		return b, nil
+	case strings.HasPrefix(data, "@"):
+		b, err := os.ReadFile(strings.TrimPrefix(data, "@"))
+		if err != nil {
+			return nil, fmt.Errorf("read payload file: %w", err)
+		}
+		return b, nil
+	default:
+		return []byte(data), nil
+	}
+}
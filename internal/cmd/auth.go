@@ -5,7 +5,12 @@ import (
 )
 
 type AuthCmd struct {
-	ImportCurl AuthImportCurlCmd `cmd:"" name:"import-curl" help:"Import auth from a pasted Notion DevTools 'Copy as cURL' request"`
+	Import        AuthImportCmd        `cmd:"" aliases:"import-curl" help:"Import auth from a copied Notion DevTools request (cURL, cmd cURL, HAR, fetch, PowerShell)"`
+	ImportBrowser AuthImportBrowserCmd `cmd:"" name:"import-browser" help:"Import auth from a local Firefox or Chromium cookie database"`
+	Status        AuthStatusCmd        `cmd:"" help:"Verify credentials against Notion and show the authenticated user and spaces"`
+	Profiles      AuthProfilesCmd      `cmd:"" help:"Manage named auth profiles"`
+	Encrypt       AuthEncryptCmd       `cmd:"" help:"Encrypt the profile's token_v2/cookie or move them to a credential helper"`
+	Decrypt       AuthDecryptCmd       `cmd:"" help:"Store the profile's token_v2/cookie as plaintext again"`
 }
 
 func redact(s string) string {
@@ -18,48 +23,6 @@ func redact(s string) string {
 	return s[:4] + "..." + s[len(s)-4:]
 }
 
-func parseCookieValue(cookieHeader string, key string) string {
-	for _, p := range splitCookieParts(cookieHeader) {
-		k, v, ok := splitKV(p)
-		if !ok {
-			continue
-		}
-		if k == key {
-			return v
-		}
-	}
-	return ""
-}
-
-func splitCookieParts(cookie string) []string {
-	parts := make([]string, 0)
-	cur := ""
-	for _, r := range cookie {
-		if r == ';' {
-			parts = append(parts, cur)
-			cur = ""
-			continue
-		}
-		cur += string(r)
-	}
-	parts = append(parts, cur)
-	return parts
-}
-
-func splitKV(s string) (string, string, bool) {
-	for i, r := range s {
-		if r == '=' {
-			k := trimSpace(s[:i])
-			v := trimSpace(s[i+1:])
-			if k == "" {
-				return "", "", false
-			}
-			return k, v, true
-		}
-	}
-	return "", "", false
-}
-
 func trimSpace(s string) string {
 	start := 0
 	end := len(s)
@@ -72,8 +35,8 @@ func trimSpace(s string) string {
 	return s[start:end]
 }
 
-func printImportSummary(path string, token string, userID string, activeUserID string, cookieStored bool) {
-	fmt.Printf("updated config: %s\n", path)
+func printImportSummary(path string, profile string, token string, userID string, activeUserID string, cookieStored bool) {
+	fmt.Printf("updated config: %s (profile %s)\n", path, profile)
 	if token != "" {
 		fmt.Printf("- token_v2: %s\n", redact(token))
 	}
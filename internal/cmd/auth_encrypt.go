@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jodok/nocli/internal/config"
+)
+
+type AuthEncryptCmd struct {
+	Helper string `name:"helper" help:"Credential helper command to delegate secrets to instead of passphrase encryption"`
+}
+
+type AuthDecryptCmd struct{}
+
+func (c *AuthEncryptCmd) Run(ctx context.Context) error {
+	path := ConfigPathFromContext(ctx)
+	cfg, err := config.Read(path)
+	if err != nil {
+		return err
+	}
+	name := ProfileFromContext(ctx)
+	profile, ok := cfg.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+
+	// Re-sealing an already locked profile rotates the passphrase or moves
+	// it between encryption and a helper.
+	if profile.Locked() {
+		if profile, err = config.Unlock(name, profile, passphraseSource(false)); err != nil {
+			return err
+		}
+	}
+	if profile.TokenV2 == "" && profile.Cookie == "" {
+		return fmt.Errorf("profile %q has no token_v2 or cookie to protect", name)
+	}
+
+	profile.CredentialHelper = strings.TrimSpace(c.Helper)
+	profile.Encrypted = ""
+	if profile, err = config.Seal(name, profile, passphraseSource(true)); err != nil {
+		return err
+	}
+	cfg.SetProfile(name, profile)
+	if err := config.Write(path, cfg); err != nil {
+		return err
+	}
+
+	if profile.CredentialHelper != "" {
+		fmt.Printf("profile %s: secrets stored via credential helper %q\n", name, profile.CredentialHelper)
+	} else {
+		fmt.Printf("profile %s: secrets encrypted in %s\n", name, path)
+	}
+	return nil
+}
+
+func (c *AuthDecryptCmd) Run(ctx context.Context) error {
+	path := ConfigPathFromContext(ctx)
+	cfg, err := config.Read(path)
+	if err != nil {
+		return err
+	}
+	name := ProfileFromContext(ctx)
+	profile, ok := cfg.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	if !profile.Locked() {
+		fmt.Printf("profile %s is not encrypted\n", name)
+		return nil
+	}
+
+	if profile, err = config.Unlock(name, profile, passphraseSource(false)); err != nil {
+		return err
+	}
+	profile.Encrypted = ""
+	profile.CredentialHelper = ""
+	cfg.SetProfile(name, profile)
+	if err := config.Write(path, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("profile %s: secrets stored as plaintext in %s\n", name, path)
+	return nil
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+var (
+	rePSCookie    = regexp.MustCompile(`New-Object\s+System\.Net\.Cookie\(\s*"([^"]*)"\s*,\s*"([^"]*)"`)
+	rePSHeader    = regexp.MustCompile(`(?i)"([a-z0-9\-]+)"\s*=\s*"((?:[^"` + "`" + `]|` + "`" + `.)*)"`)
+	reCmdContinue = regexp.MustCompile(`\^\r?\n`)
+)
+
+// detectAuthFormat guesses which DevTools "Copy as ..." flavor raw is.
+func detectAuthFormat(raw string) string {
+	s := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(s, "{") && strings.Contains(s, `"log"`):
+		return "har"
+	case strings.Contains(s, "fetch("):
+		return "fetch"
+	case strings.Contains(s, "Invoke-WebRequest") || strings.Contains(s, "System.Net.Cookie") || strings.Contains(s, "$session"):
+		return "powershell"
+	case strings.Contains(s, `^"`):
+		return "curl-cmd"
+	default:
+		return "curl"
+	}
+}
+
+// parseAuthInput extracts Notion auth values from a copied request in the
+// given format.
+func parseAuthInput(raw string, format string) (token string, userID string, activeUserID string, cookieHeader string, err error) {
+	switch format {
+	case "curl":
+		token, userID, activeUserID, cookieHeader = parseCurlAuth(raw)
+		return token, userID, activeUserID, cookieHeader, nil
+	case "curl-cmd":
+		token, userID, activeUserID, cookieHeader = parseCurlAuth(unescapeCmdCurl(raw))
+		return token, userID, activeUserID, cookieHeader, nil
+	case "har":
+		headers, err := harRequestHeaders(raw)
+		if err != nil {
+			return "", "", "", "", err
+		}
+		token, userID, activeUserID, cookieHeader = authFromHeaders(headers)
+		return token, userID, activeUserID, cookieHeader, nil
+	case "fetch":
+		headers, err := fetchRequestHeaders(raw)
+		if err != nil {
+			return "", "", "", "", err
+		}
+		token, userID, activeUserID, cookieHeader = authFromHeaders(headers)
+		return token, userID, activeUserID, cookieHeader, nil
+	case "powershell":
+		token, userID, activeUserID, cookieHeader = authFromHeaders(powerShellHeaders(raw))
+		return token, userID, activeUserID, cookieHeader, nil
+	default:
+		return "", "", "", "", fmt.Errorf("unsupported auth input format %q", format)
+	}
+}
+
+// authFromHeaders reads auth values from lower-cased request headers.
+func authFromHeaders(headers map[string]string) (token string, userID string, activeUserID string, cookieHeader string) {
+	cookieHeader = trimSpace(headers["cookie"])
+	if cookieHeader != "" {
+		token = notionclient.ParseCookieValue(cookieHeader, "token_v2")
+		userID = notionclient.ParseCookieValue(cookieHeader, "notion_user_id")
+	}
+	activeUserID = trimSpace(headers["x-notion-active-user-header"])
+	return token, userID, activeUserID, cookieHeader
+}
+
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				Cookies []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"cookies"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// harRequestHeaders picks the first /api/v3 request carrying token_v2 (or any
+// request with it) and returns its headers, with a Cookie header rebuilt from
+// the cookies list when the export omitted it.
+func harRequestHeaders(raw string) (map[string]string, error) {
+	var har harFile
+	if err := json.Unmarshal([]byte(raw), &har); err != nil {
+		return nil, fmt.Errorf("parse HAR: %w", err)
+	}
+
+	var best map[string]string
+	for _, e := range har.Log.Entries {
+		headers := map[string]string{}
+		for _, h := range e.Request.Headers {
+			headers[strings.ToLower(strings.TrimPrefix(h.Name, ":"))] = h.Value
+		}
+		if headers["cookie"] == "" && len(e.Request.Cookies) > 0 {
+			parts := make([]string, 0, len(e.Request.Cookies))
+			for _, ck := range e.Request.Cookies {
+				parts = append(parts, ck.Name+"="+ck.Value)
+			}
+			headers["cookie"] = strings.Join(parts, "; ")
+		}
+		if notionclient.ParseCookieValue(headers["cookie"], "token_v2") == "" {
+			continue
+		}
+		if strings.Contains(e.Request.URL, "/api/v3/") {
+			return headers, nil
+		}
+		if best == nil {
+			best = headers
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("HAR contains no request with a token_v2 cookie")
+	}
+	return best, nil
+}
+
+// fetchRequestHeaders decodes the options object of a DevTools
+// "Copy as fetch (Node.js)" snippet: fetch("url", { "headers": {...}, ... }).
+func fetchRequestHeaders(raw string) (map[string]string, error) {
+	start := strings.Index(raw, "fetch(")
+	if start < 0 {
+		return nil, fmt.Errorf("no fetch( call found")
+	}
+	rest := raw[start:]
+	brace := strings.Index(rest, "{")
+	if brace < 0 {
+		return nil, fmt.Errorf("fetch call has no options object")
+	}
+
+	var opts struct {
+		Headers map[string]string `json:"headers"`
+	}
+	if err := json.NewDecoder(strings.NewReader(rest[brace:])).Decode(&opts); err != nil {
+		return nil, fmt.Errorf("parse fetch options: %w", err)
+	}
+	headers := make(map[string]string, len(opts.Headers))
+	for k, v := range opts.Headers {
+		headers[strings.ToLower(k)] = v
+	}
+	return headers, nil
+}
+
+// powerShellHeaders collects the -Headers @{...} entries and the cookies added
+// to the web session of a "Copy as PowerShell" snippet.
+func powerShellHeaders(raw string) map[string]string {
+	headers := map[string]string{}
+	for _, m := range rePSHeader.FindAllStringSubmatch(raw, -1) {
+		headers[strings.ToLower(m[1])] = unescapePowerShell(m[2])
+	}
+	cookies := make([]string, 0)
+	for _, m := range rePSCookie.FindAllStringSubmatch(raw, -1) {
+		cookies = append(cookies, m[1]+"="+unescapePowerShell(m[2]))
+	}
+	if len(cookies) > 0 && headers["cookie"] == "" {
+		headers["cookie"] = strings.Join(cookies, "; ")
+	}
+	return headers
+}
+
+func unescapePowerShell(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '`' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// unescapeCmdCurl turns Windows cmd "Copy as cURL (cmd)" quoting back into
+// plain double-quoted arguments: ^ escapes the next character and a trailing
+// ^ continues the line.
+func unescapeCmdCurl(raw string) string {
+	s := reCmdContinue.ReplaceAllString(raw, " ")
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '^' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	// cmd output writes literal quotes inside arguments as \"; drop the
+	// backslash so the cookie regexes see plain values.
+	return strings.ReplaceAll(b.String(), `\"`, `"`)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import "testing"
+
+const (
+	testUserID   = "11111111-1111-4111-8111-111111111111"
+	testActiveID = "22222222-2222-4222-8222-222222222222"
+)
+
+type authCase struct {
+	name   string
+	raw    string
+	token  string
+	user   string
+	active string
+	cookie string
+}
+
+func checkAuthCases(t *testing.T, format string, tests []authCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectAuthFormat(tt.raw); got != format {
+				t.Errorf("detectAuthFormat = %q, want %q", got, format)
+			}
+			token, user, active, cookie, err := parseAuthInput(tt.raw, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != tt.token || user != tt.user || active != tt.active {
+				t.Errorf("got token=%q user=%q active=%q, want token=%q user=%q active=%q", token, user, active, tt.token, tt.user, tt.active)
+			}
+			if tt.cookie != "" && cookie != tt.cookie {
+				t.Errorf("cookie = %q, want %q", cookie, tt.cookie)
+			}
+		})
+	}
+}
+
+func TestParseCurlAuth(t *testing.T) {
+	checkAuthCases(t, "curl", []authCase{
+		{
+			name:   "header",
+			raw:    `curl 'https://www.notion.so/api/v3/loadUserContent' -H 'cookie: token_v2=abc; notion_user_id=` + testUserID + `'`,
+			token:  "abc",
+			user:   testUserID,
+			cookie: "token_v2=abc; notion_user_id=" + testUserID,
+		},
+		{
+			name: "multi-line",
+			raw: "curl 'https://www.notion.so/api/v3/getSpaces' \\\n" +
+				"  -H 'accept: */*' \\\n" +
+				"  -H 'cookie: notion_browser_id=x; token_v2=v02%3Auser_token; notion_user_id=" + testUserID + "' \\\n" +
+				"  -H 'x-notion-active-user-header: " + testActiveID + "' \\\n" +
+				"  --data-raw '{}'",
+			token:  "v02%3Auser_token",
+			user:   testUserID,
+			active: testActiveID,
+		},
+		{
+			name:  "cookie flag",
+			raw:   `curl https://www.notion.so/api/v3/getSpaces -b "token_v2=abc; notion_user_id=` + testUserID + `"`,
+			token: "abc",
+			user:  testUserID,
+		},
+		{
+			name:  "escaped quote in another header",
+			raw:   `curl 'https://www.notion.so/api/v3/getSpaces' -H 'sec-ch-ua: "Chromium";v="128"' -H 'cookie: token_v2=abc' --data-raw '{"q":"it'\''s"}'`,
+			token: "abc",
+		},
+	})
+}
+
+func TestParseCmdCurlAuth(t *testing.T) {
+	checkAuthCases(t, "curl-cmd", []authCase{
+		{
+			name: "multi-line",
+			raw: "curl ^\"https://www.notion.so/api/v3/getSpaces^\" ^\r\n" +
+				"  -H ^\"accept: */*^\" ^\r\n" +
+				"  -H ^\"cookie: token_v2=abc; notion_user_id=" + testUserID + "^\" ^\r\n" +
+				"  -H ^\"x-notion-active-user-header: " + testActiveID + "^\" ^\n" +
+				"  --data-raw ^\"^{^}^\"",
+			token:  "abc",
+			user:   testUserID,
+			active: testActiveID,
+			cookie: "token_v2=abc; notion_user_id=" + testUserID,
+		},
+		{
+			name:  "escaped quotes and percent",
+			raw:   `curl ^"https://www.notion.so/api/v3/getSpaces^" -H ^"cookie: token_v2=v02^%^3Aabc; notion_user_id=` + testUserID + `^" --data-raw ^"^{^\^"spaceId^\^":^\^"x^\^"^}^"`,
+			token: "v02%3Aabc",
+			user:  testUserID,
+		},
+	})
+}
+
+func TestParseHARAuth(t *testing.T) {
+	checkAuthCases(t, "har", []authCase{
+		{
+			name: "prefers api request",
+			raw: `{"log": {"entries": [
+				{"request": {"url": "https://www.notion.so/", "headers": [{"name": "cookie", "value": "token_v2=page"}]}},
+				{"request": {"url": "https://www.notion.so/api/v3/getSpaces", "headers": [
+					{"name": ":authority", "value": "www.notion.so"},
+					{"name": "Cookie", "value": "token_v2=api; notion_user_id=` + testUserID + `"},
+					{"name": "X-Notion-Active-User-Header", "value": "` + testActiveID + `"}
+				]}}
+			]}}`,
+			token:  "api",
+			user:   testUserID,
+			active: testActiveID,
+		},
+		{
+			name: "cookies list and escaped quotes",
+			raw: `{"log": {"entries": [
+				{"request": {"url": "https://www.notion.so/api/v3/getSpaces",
+					"headers": [{"name": "sec-ch-ua", "value": "\"Chromium\";v=\"128\""}],
+					"cookies": [{"name": "token_v2", "value": "abc"}, {"name": "notion_user_id", "value": "` + testUserID + `"}]}}
+			]}}`,
+			token:  "abc",
+			user:   testUserID,
+			cookie: "token_v2=abc; notion_user_id=" + testUserID,
+		},
+	})
+
+	if _, _, _, _, err := parseAuthInput(`{"log": {"entries": [{"request": {"url": "https://www.notion.so/"}}]}}`, "har"); err == nil {
+		t.Error("HAR without token_v2: want error")
+	}
+	if _, _, _, _, err := parseAuthInput(`{"log": [`, "har"); err == nil {
+		t.Error("truncated HAR: want error")
+	}
+}
+
+func TestParseFetchAuth(t *testing.T) {
+	checkAuthCases(t, "fetch", []authCase{
+		{
+			name: "node",
+			raw: `fetch("https://www.notion.so/api/v3/getSpaces", {
+  "headers": {
+    "accept": "*/*",
+    "Cookie": "token_v2=abc; notion_user_id=` + testUserID + `",
+    "x-notion-active-user-header": "` + testActiveID + `"
+  },
+  "body": "{}",
+  "method": "POST"
+});`,
+			token:  "abc",
+			user:   testUserID,
+			active: testActiveID,
+		},
+		{
+			name:  "escaped quotes",
+			raw:   `await fetch("https://www.notion.so/api/v3/getSpaces", {"headers": {"sec-ch-ua": "\"Chromium\";v=\"128\"", "cookie": "token_v2=abc"}, "body": "{\"a\":\"}\"}", "method": "POST"});`,
+			token: "abc",
+		},
+	})
+
+	if _, _, _, _, err := parseAuthInput(`fetch("https://www.notion.so/")`, "fetch"); err == nil {
+		t.Error("fetch without options: want error")
+	}
+}
+
+func TestParsePowerShellAuth(t *testing.T) {
+	checkAuthCases(t, "powershell", []authCase{
+		{
+			name: "session cookies",
+			raw: "$session = New-Object Microsoft.PowerShell.Commands.WebRequestSession\n" +
+				"$session.Cookies.Add((New-Object System.Net.Cookie(\"token_v2\", \"abc\", \"/\", \".notion.so\")))\n" +
+				"$session.Cookies.Add((New-Object System.Net.Cookie(\"notion_user_id\", \"" + testUserID + "\", \"/\", \".notion.so\")))\n" +
+				"Invoke-WebRequest -UseBasicParsing -Uri \"https://www.notion.so/api/v3/getSpaces\" `\n" +
+				"-Method POST `\n" +
+				"-WebSession $session `\n" +
+				"-Headers @{\n" +
+				"  \"accept\"=\"*/*\"\n" +
+				"  \"x-notion-active-user-header\"=\"" + testActiveID + "\"\n" +
+				"} `\n" +
+				"-Body \"{}\"",
+			token:  "abc",
+			user:   testUserID,
+			active: testActiveID,
+			cookie: "token_v2=abc; notion_user_id=" + testUserID,
+		},
+		{
+			name: "escaped quotes",
+			raw: "Invoke-WebRequest -Uri \"https://www.notion.so/api/v3/getSpaces\" `\n" +
+				"-Headers @{\n" +
+				"  \"sec-ch-ua\"=\"`\"Chromium`\";v=`\"128`\"\"\n" +
+				"  \"cookie\"=\"token_v2=abc; notion_user_id=" + testUserID + "\"\n" +
+				"}",
+			token: "abc",
+			user:  testUserID,
+		},
+	})
+}
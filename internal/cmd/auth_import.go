@@ -9,30 +9,40 @@ import (
 	"strings"
 
 	"github.com/jodok/nocli/internal/config"
+	"github.com/jodok/nocli/internal/notionclient"
 )
 
 var (
 	reCookieHeader = regexp.MustCompile(`(?im)cookie\s*:\s*([^\r\n"']+)`)
+	reCookieFlag   = regexp.MustCompile(`(?:^|\s)(?:-b|--cookie)\s+(?:'([^']*)'|"([^"]*)")`)
 	reTokenV2      = regexp.MustCompile(`(?i)token_v2=([^;\s"']+)`)
 	reUserID       = regexp.MustCompile(`(?i)notion_user_id=([0-9a-f\-]{32,36})`)
 	reActiveUser   = regexp.MustCompile(`(?im)x-notion-active-user-header\s*:\s*([0-9a-f\-]{32,36})`)
 	reNotionURL    = regexp.MustCompile(`https://www\.notion\.so`)
 )
 
-type AuthImportCurlCmd struct {
-	InputPath   string `name:"input" short:"i" help:"Path to a text file containing copied cURL"`
+type AuthImportCmd struct {
+	InputPath   string `name:"input" short:"i" help:"Path to a file containing the copied request (cURL, HAR, fetch, PowerShell)"`
+	Format      string `name:"format" enum:"auto,curl,curl-cmd,har,fetch,powershell" default:"auto" help:"Input format; auto detects it"`
 	StoreCookie bool   `name:"store-cookie" help:"Also store full Cookie header in config"`
 }
 
-func (c *AuthImportCurlCmd) Run(ctx context.Context) error {
+func (c *AuthImportCmd) Run(ctx context.Context) error {
 	raw, err := c.readInput()
 	if err != nil {
 		return err
 	}
 
-	token, userID, activeUserID, cookieHeader := parseCurlAuth(raw)
+	format := c.Format
+	if format == "auto" {
+		format = detectAuthFormat(raw)
+	}
+	token, userID, activeUserID, cookieHeader, err := parseAuthInput(raw, format)
+	if err != nil {
+		return err
+	}
 	if token == "" && userID == "" && activeUserID == "" && cookieHeader == "" {
-		return fmt.Errorf("no notion auth values found; paste a full 'Copy as cURL' request including headers")
+		return fmt.Errorf("no notion auth values found in %s input; copy a full Notion /api/v3 request including headers", format)
 	}
 
 	path := ConfigPathFromContext(ctx)
@@ -41,34 +51,51 @@ func (c *AuthImportCurlCmd) Run(ctx context.Context) error {
 		return err
 	}
 
+	profileName := ProfileFromContext(ctx)
+	profile, _ := cfg.Lookup(profileName)
+	locked := profile.Locked()
+	// One source for Unlock and Seal: the user is asked once and the profile
+	// is re-sealed with the passphrase that just opened it.
+	passphrase := passphraseSource(false)
+	if locked {
+		if profile, err = config.Unlock(profileName, profile, passphrase); err != nil {
+			return err
+		}
+	}
 	if token != "" {
-		cfg.TokenV2 = token
+		profile.TokenV2 = token
 	}
 	if userID != "" {
-		cfg.NotionUserID = userID
+		profile.NotionUserID = userID
 	}
 	if activeUserID != "" {
-		cfg.ActiveUserID = activeUserID
+		profile.ActiveUserID = activeUserID
 	}
 	if c.StoreCookie && cookieHeader != "" {
-		cfg.Cookie = cookieHeader
+		profile.Cookie = cookieHeader
 	}
-	if cfg.BaseURL == "" && reNotionURL.MatchString(raw) {
-		cfg.BaseURL = "https://www.notion.so"
+	if profile.BaseURL == "" && reNotionURL.MatchString(raw) {
+		profile.BaseURL = "https://www.notion.so"
+	}
+	if locked {
+		if profile, err = config.Seal(profileName, profile, passphrase); err != nil {
+			return err
+		}
 	}
+	cfg.SetProfile(profileName, profile)
 
 	if err := config.Write(path, cfg); err != nil {
 		return err
 	}
 
-	printImportSummary(path, token, userID, activeUserID, c.StoreCookie && cookieHeader != "")
+	printImportSummary(path, profileName, token, userID, activeUserID, c.StoreCookie && cookieHeader != "")
 	if token == "" || userID == "" {
 		fmt.Printf("warning: token_v2 or notion_user_id missing from pasted data\n")
 	}
 	return nil
 }
 
-func (c *AuthImportCurlCmd) readInput() (string, error) {
+func (c *AuthImportCmd) readInput() (string, error) {
 	if strings.TrimSpace(c.InputPath) != "" {
 		b, err := os.ReadFile(c.InputPath)
 		if err != nil {
@@ -82,7 +109,7 @@ func (c *AuthImportCurlCmd) readInput() (string, error) {
 		return "", fmt.Errorf("stat stdin: %w", err)
 	}
 	if (st.Mode() & os.ModeCharDevice) != 0 {
-		fmt.Fprintln(os.Stderr, "Paste Notion 'Copy as cURL' output, then press Ctrl-D:")
+		fmt.Fprintln(os.Stderr, "Paste a copied Notion request (cURL, fetch, PowerShell or HAR), then press Ctrl-D:")
 	}
 
 	b, err := io.ReadAll(os.Stdin)
@@ -98,11 +125,13 @@ func (c *AuthImportCurlCmd) readInput() (string, error) {
 func parseCurlAuth(raw string) (token string, userID string, activeUserID string, cookieHeader string) {
 	if m := reCookieHeader.FindStringSubmatch(raw); len(m) > 1 {
 		cookieHeader = trimSpace(m[1])
+	} else if m := reCookieFlag.FindStringSubmatch(raw); len(m) > 2 {
+		cookieHeader = trimSpace(m[1] + m[2])
 	}
 
 	if cookieHeader != "" {
-		token = parseCookieValue(cookieHeader, "token_v2")
-		userID = parseCookieValue(cookieHeader, "notion_user_id")
+		token = notionclient.ParseCookieValue(cookieHeader, "token_v2")
+		userID = notionclient.ParseCookieValue(cookieHeader, "notion_user_id")
 	}
 
 	if token == "" {
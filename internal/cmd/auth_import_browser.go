@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jodok/nocli/internal/browsercookies"
+	"github.com/jodok/nocli/internal/config"
+)
+
+type AuthImportBrowserCmd struct {
+	Browser     string `name:"browser" enum:"firefox,chromium" required:"" help:"Browser to read cookies from"`
+	ProfileDir  string `name:"profile-dir" help:"Browser profile directory or cookie database (default: most recently used profile)"`
+	StoreCookie bool   `name:"store-cookie" help:"Also store all notion.so cookies as the Cookie header in config"`
+}
+
+func (c *AuthImportBrowserCmd) Run(ctx context.Context) error {
+	dbPath, err := browsercookies.Find(c.Browser, strings.TrimSpace(c.ProfileDir))
+	if err != nil {
+		return err
+	}
+	cookies, err := browsercookies.Read(c.Browser, dbPath, "notion.so")
+	if err != nil {
+		return fmt.Errorf("read %s cookies from %s: %w", c.Browser, dbPath, err)
+	}
+
+	var token, userID string
+	parts := make([]string, 0, len(cookies))
+	for _, ck := range cookies {
+		switch ck.Name {
+		case "token_v2":
+			token = ck.Value
+		case "notion_user_id":
+			userID = ck.Value
+		}
+		parts = append(parts, ck.Name+"="+ck.Value)
+	}
+	if token == "" {
+		return fmt.Errorf("no token_v2 cookie for notion.so in %s; sign in to Notion in %s first", dbPath, c.Browser)
+	}
+	cookieHeader := strings.Join(parts, "; ")
+
+	path := ConfigPathFromContext(ctx)
+	cfg, err := config.Read(path)
+	if err != nil {
+		return err
+	}
+	profileName := ProfileFromContext(ctx)
+	profile, _ := cfg.Lookup(profileName)
+	locked := profile.Locked()
+	passphrase := passphraseSource(false)
+	if locked {
+		if profile, err = config.Unlock(profileName, profile, passphrase); err != nil {
+			return err
+		}
+	}
+
+	profile.TokenV2 = token
+	if userID != "" {
+		profile.NotionUserID = userID
+	}
+	if c.StoreCookie {
+		profile.Cookie = cookieHeader
+	}
+	if locked {
+		if profile, err = config.Seal(profileName, profile, passphrase); err != nil {
+			return err
+		}
+	}
+	cfg.SetProfile(profileName, profile)
+	if err := config.Write(path, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("read cookies: %s\n", dbPath)
+	printImportSummary(path, profileName, token, userID, "", c.StoreCookie)
+	if userID == "" {
+		fmt.Printf("warning: notion_user_id cookie missing\n")
+	}
+	return nil
+}
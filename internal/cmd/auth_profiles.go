@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jodok/nocli/internal/config"
+)
+
+type AuthProfilesCmd struct {
+	List   AuthProfilesListCmd   `cmd:"" default:"1" help:"List configured profiles"`
+	Use    AuthProfilesUseCmd    `cmd:"" help:"Set the default profile"`
+	Remove AuthProfilesRemoveCmd `cmd:"" help:"Delete a profile"`
+}
+
+type AuthProfilesListCmd struct{}
+
+type AuthProfilesUseCmd struct {
+	Name string `arg:"" name:"name" help:"Profile name"`
+}
+
+type AuthProfilesRemoveCmd struct {
+	Name string `arg:"" name:"name" help:"Profile name"`
+}
+
+func (c *AuthProfilesListCmd) Run(ctx context.Context) error {
+	path := ConfigPathFromContext(ctx)
+	cfg, err := config.Read(path)
+	if err != nil {
+		return err
+	}
+
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		fmt.Printf("no profiles in %s; run 'nocli auth import --profile <name>'\n", path)
+		return nil
+	}
+	current := cfg.ProfileName("")
+	for _, name := range names {
+		p, _ := cfg.Lookup(name)
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		user := p.NotionUserID
+		if user == "" {
+			user = "-"
+		}
+		token := redact(p.TokenV2)
+		if token == "" && p.Cookie != "" {
+			token = "cookie"
+		}
+		fmt.Printf("%s %-16s user=%s token=%s\n", marker, name, user, token)
+	}
+	return nil
+}
+
+func (c *AuthProfilesUseCmd) Run(ctx context.Context) error {
+	path := ConfigPathFromContext(ctx)
+	cfg, err := config.Read(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Lookup(c.Name); !ok {
+		return fmt.Errorf("unknown profile %q", c.Name)
+	}
+
+	cfg.DefaultProfile = c.Name
+	if _, named := cfg.Profiles[c.Name]; !named && c.Name == config.DefaultProfileName {
+		cfg.DefaultProfile = ""
+	}
+	if err := config.Write(path, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("default profile: %s\n", c.Name)
+	return nil
+}
+
+func (c *AuthProfilesRemoveCmd) Run(ctx context.Context) error {
+	path := ConfigPathFromContext(ctx)
+	cfg, err := config.Read(path)
+	if err != nil {
+		return err
+	}
+	if !cfg.RemoveProfile(c.Name) {
+		return fmt.Errorf("unknown profile %q", c.Name)
+	}
+	if err := config.Write(path, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("removed profile: %s\n", c.Name)
+	return nil
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type AuthStatusCmd struct {
+	JSON bool `name:"json" help:"Emit the status report as JSON"`
+}
+
+type authStatusUser struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type authStatusSpace struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	UserID string `json:"user_id"`
+}
+
+type authStatusReport struct {
+	OK                bool              `json:"ok"`
+	Token             string            `json:"token"`
+	User              *authStatusUser   `json:"user,omitempty"`
+	LoggedInUsers     []authStatusUser  `json:"logged_in_users"`
+	CookieUserIDs     []string          `json:"cookie_user_ids"`
+	ActiveUserID      string            `json:"active_user_id"`
+	ActiveUserSource  string            `json:"active_user_source"`
+	ActiveUserMatches bool              `json:"active_user_matches_token"`
+	Spaces            []authStatusSpace `json:"spaces"`
+	Problems          []string          `json:"problems,omitempty"`
+	Warnings          []string          `json:"warnings,omitempty"`
+}
+
+func (c *AuthStatusCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	report := checkAuthStatus(ctx, client)
+	if c.JSON {
+		if err := writeJSON("", report); err != nil {
+			return err
+		}
+	} else {
+		printAuthStatus(report)
+	}
+	if !report.OK {
+		return fmt.Errorf("auth check failed: %s", report.Problems[0])
+	}
+	return nil
+}
+
+func checkAuthStatus(ctx context.Context, client *notionclient.Client) authStatusReport {
+	report := authStatusReport{
+		Token:         "missing",
+		LoggedInUsers: []authStatusUser{},
+		Spaces:        []authStatusSpace{},
+		ActiveUserID:  client.CurrentUserID(),
+		CookieUserIDs: client.LoggedInUserIDs(),
+	}
+	if report.CookieUserIDs == nil {
+		report.CookieUserIDs = []string{}
+	}
+	switch {
+	case client.ActiveUserID() != "":
+		report.ActiveUserSource = "active_user_id"
+	case report.ActiveUserID != "":
+		report.ActiveUserSource = "notion_user_id cookie"
+	default:
+		report.ActiveUserSource = "none"
+	}
+	if !client.HasCredentials() {
+		report.Problems = append(report.Problems, "no token_v2 or cookie configured; run 'nocli auth import'")
+		return report
+	}
+
+	spaces, err := client.GetSpaces(ctx)
+	if err != nil {
+		var apiErr *notionclient.APIError
+		if errors.As(err, &apiErr) && apiErr.Unauthorized() {
+			report.Token = "expired_or_invalid"
+			report.Problems = append(report.Problems, "token_v2 was rejected by Notion (expired or revoked); re-import it")
+		} else {
+			report.Token = "unknown"
+			report.Problems = append(report.Problems, fmt.Sprintf("getSpaces failed: %v", err))
+		}
+		return report
+	}
+	report.Token = "valid"
+
+	flat := notionclient.FlattenSpaces(spaces)
+	for _, userID := range notionclient.SortedKeys(spaces) {
+		u := flat["notion_user"][userID]
+		name, _ := u["name"].(string)
+		email, _ := u["email"].(string)
+		report.LoggedInUsers = append(report.LoggedInUsers, authStatusUser{ID: userID, Name: name, Email: email})
+
+		tables, _ := spaces[userID].(map[string]any)
+		userSpaces := notionclient.FlattenRecordMap(map[string]any{"recordMap": tables})["space"]
+		for _, spaceID := range notionclient.SortedKeys(userSpaces) {
+			spaceName, _ := userSpaces[spaceID]["name"].(string)
+			report.Spaces = append(report.Spaces, authStatusSpace{ID: spaceID, Name: spaceName, UserID: userID})
+		}
+	}
+
+	for i := range report.LoggedInUsers {
+		if report.LoggedInUsers[i].ID == report.ActiveUserID {
+			report.ActiveUserMatches = true
+			report.User = &report.LoggedInUsers[i]
+		}
+	}
+	switch {
+	case len(report.LoggedInUsers) == 0:
+		report.Problems = append(report.Problems, "token is valid but no logged-in users were returned")
+	case report.ActiveUserID == "":
+		// Without a notion_user_id cookie Notion acts as the token's user,
+		// which is only ambiguous when several users are logged in.
+		report.User = &report.LoggedInUsers[0]
+		if len(report.LoggedInUsers) > 1 {
+			report.Warnings = append(report.Warnings, "no active user configured and several users are logged in; set active_user_id or notion_user_id")
+		}
+	case !report.ActiveUserMatches:
+		report.Problems = append(report.Problems, fmt.Sprintf("active user %s (%s) is not logged in with this token", report.ActiveUserID, report.ActiveUserSource))
+	}
+	if len(report.Spaces) == 0 && len(report.Problems) == 0 {
+		report.Problems = append(report.Problems, "no spaces are reachable with this token")
+	}
+
+	report.OK = len(report.Problems) == 0
+	return report
+}
+
+func printAuthStatus(r authStatusReport) {
+	fmt.Printf("token_v2: %s\n", r.Token)
+	if r.User != nil {
+		fmt.Printf("user: %s\n", formatStatusUser(*r.User))
+	}
+	if r.ActiveUserID != "" {
+		state := "not logged in with this token"
+		if r.ActiveUserMatches {
+			state = "ok"
+		}
+		fmt.Printf("active user: %s (from %s, %s)\n", r.ActiveUserID, r.ActiveUserSource, state)
+	} else if r.Token == "valid" {
+		fmt.Printf("active user: none configured\n")
+	}
+	if len(r.LoggedInUsers) > 1 {
+		fmt.Printf("logged-in users:\n")
+		for _, u := range r.LoggedInUsers {
+			fmt.Printf("  - %s\n", formatStatusUser(u))
+		}
+	}
+	if len(r.Spaces) > 0 {
+		fmt.Printf("spaces:\n")
+		for _, s := range r.Spaces {
+			fmt.Printf("  - %s (%s) as %s\n", s.Name, s.ID, s.UserID)
+		}
+	}
+	for _, w := range r.Warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+	for _, p := range r.Problems {
+		fmt.Printf("problem: %s\n", p)
+	}
+}
+
+func formatStatusUser(u authStatusUser) string {
+	switch {
+	case u.Name != "" && u.Email != "":
+		return fmt.Sprintf("%s <%s> (%s)", u.Name, u.Email, u.ID)
+	case u.Email != "":
+		return fmt.Sprintf("%s (%s)", u.Email, u.ID)
+	default:
+		return u.ID
+	}
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jodok/nocli/internal/archive"
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type BackupCmd struct {
+	Space           string `name:"space" required:"" help:"Space (workspace) ID to back up"`
+	Out             string `name:"out" required:"" help:"Archive directory; an unfinished archive there is resumed"`
+	IncrementalFrom string `name:"incremental-from" help:"Previous archive; unchanged records are referenced instead of copied"`
+	RowLimit        int    `name:"row-limit" default:"10000" help:"Maximum rows fetched per database"`
+}
+
+// backupSaveEvery is how many new records are written between manifest saves.
+const backupSaveEvery = 200
+
+func (c *BackupCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	spaceID, err := notionclient.ParsePageID(c.Space)
+	if err != nil {
+		return fmt.Errorf("parse space id: %w", err)
+	}
+
+	client = client.ForSpace(ctx, spaceID)
+
+	arc, resumed, err := c.openArchive(spaceID)
+	if err != nil {
+		return err
+	}
+	if resumed {
+		_, _ = fmt.Fprintf(os.Stderr, "resuming unfinished backup in %s\n", c.Out)
+	}
+
+	roots, err := backupSpaceRecords(ctx, client, arc, spaceID)
+	if err != nil {
+		return err
+	}
+	if len(roots) == 0 {
+		return fmt.Errorf("space %s has no top-level pages visible to this user", spaceID)
+	}
+	arc.Manifest().Roots = roots
+
+	changed, err := changedSinceBase(ctx, client, arc)
+	if err != nil {
+		return err
+	}
+
+	written, reused, pending := 0, 0, 0
+	tree, err := client.FetchTree(ctx, roots, notionclient.TreeOptions{
+		IncludeCollections: true,
+		RowLimit:           c.RowLimit,
+		Cached: func(table string, id string) (map[string]any, bool) {
+			if v, ok, err := arc.Get(table, id); ok && err == nil {
+				return v, true
+			}
+			return baseRecord(arc, changed, table, id)
+		},
+		OnRecord: func(table string, id string, value map[string]any) error {
+			stored, err := storeBackupRecord(arc, table, id, value)
+			if err != nil {
+				return err
+			}
+			switch stored {
+			case "written":
+				written++
+			case "reused":
+				reused++
+			default:
+				return nil
+			}
+			if pending++; pending >= backupSaveEvery {
+				pending = 0
+				_, _ = fmt.Fprintf(os.Stderr, "backup: %d records written, %d reused\n", written, reused)
+				return arc.SaveManifest()
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		if serr := arc.SaveManifest(); serr != nil {
+			_, _ = fmt.Fprintln(os.Stderr, serr)
+		}
+		return fmt.Errorf("backup interrupted (re-run the same command to resume): %w", err)
+	}
+
+	arc.Manifest().Missing = tree.Missing
+	if err := arc.Finish(); err != nil {
+		return err
+	}
+
+	counts := map[string]int{}
+	for table, rows := range arc.Manifest().Records {
+		counts[table] = len(rows)
+	}
+	return writeOutput(ctx, "", map[string]any{
+		"archive":  c.Out,
+		"space_id": spaceID,
+		"base":     arc.Manifest().Base,
+		"roots":    roots,
+		"counts":   counts,
+		"written":  written,
+		"reused":   reused,
+		"missing":  len(tree.Missing),
+	})
+}
+
+func (c *BackupCmd) openArchive(spaceID string) (*archive.Archive, bool, error) {
+	if _, err := os.Stat(filepath.Join(c.Out, "manifest.json")); err != nil {
+		arc, err := archive.Create(c.Out, spaceID, strings.TrimSpace(c.IncrementalFrom))
+		return arc, false, err
+	}
+
+	arc, err := archive.Open(c.Out)
+	if err != nil {
+		return nil, false, err
+	}
+	m := arc.Manifest()
+	if m.Complete {
+		return nil, false, fmt.Errorf("%s already holds a complete backup; choose a new --out directory", c.Out)
+	}
+	if m.SpaceID != spaceID {
+		return nil, false, fmt.Errorf("%s holds an unfinished backup of space %s, not %s", c.Out, m.SpaceID, spaceID)
+	}
+	if base := strings.TrimSpace(c.IncrementalFrom); base != "" {
+		if abs, _ := filepath.Abs(base); abs != m.Base {
+			return nil, false, fmt.Errorf("%s was started against base %q; resume with the same --incremental-from", c.Out, m.Base)
+		}
+	}
+	return arc, true, nil
+}
+
+// backupSpaceRecords stores the space, its teamspaces and the user's space
+// view, and returns the top-level page IDs found in them.
+func backupSpaceRecords(ctx context.Context, client *notionclient.Client, arc *archive.Archive, spaceID string) ([]string, error) {
+	spaces, err := client.GetSpaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load spaces: %w", err)
+	}
+	flat := notionclient.FlattenSpaces(spaces)
+
+	space := flat["space"][spaceID]
+	if len(space) == 0 {
+		resp, err := client.SyncRecords(ctx, "space", []string{spaceID})
+		if err != nil {
+			return nil, fmt.Errorf("fetch space: %w", err)
+		}
+		space = notionclient.FlattenRecordMap(resp)["space"][spaceID]
+	}
+	if len(space) == 0 {
+		return nil, fmt.Errorf("space %s not found or not accessible", spaceID)
+	}
+	if _, err := storeBackupRecord(arc, "space", spaceID, space); err != nil {
+		return nil, err
+	}
+
+	roots := make([]string, 0)
+	seen := map[string]bool{}
+	addRoots := func(v any) {
+		for _, id := range notionclient.StringList(v) {
+			if !seen[id] {
+				seen[id] = true
+				roots = append(roots, id)
+			}
+		}
+	}
+	addRoots(space["pages"])
+
+	for _, table := range []string{"team", "space_view"} {
+		for _, id := range notionclient.SortedKeys(flat[table]) {
+			row := flat[table][id]
+			if sid, _ := row["space_id"].(string); sid != spaceID {
+				continue
+			}
+			if _, err := storeBackupRecord(arc, table, id, row); err != nil {
+				return nil, err
+			}
+			addRoots(row["team_pages"])
+			addRoots(row["private_pages"])
+		}
+	}
+	return roots, nil
+}
+
+// backupSyncTables are the tables FetchTree resolves through Cached.
+var backupSyncTables = []string{"block", "collection", "collection_view"}
+
+// changedSinceBase re-syncs the base archive's records at their archived
+// versions and returns, per table, those whose version moved since. The
+// others can be read from the base instead of fetched again.
+func changedSinceBase(ctx context.Context, client *notionclient.Client, arc *archive.Archive) (map[string]map[string]map[string]any, error) {
+	base := arc.Base()
+	if base == nil {
+		return nil, nil
+	}
+	out := map[string]map[string]map[string]any{}
+	for _, table := range backupSyncTables {
+		versions := map[string]int64{}
+		for id, e := range base.Manifest().Records[table] {
+			if _, ok := arc.Entry(table, id); !ok && e.Version > 0 {
+				versions[id] = e.Version
+			}
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "backup: checking %d %s record(s) of the base archive for changes\n", len(versions), table)
+		changed, err := client.ChangedRecords(ctx, table, versions)
+		if err != nil {
+			return nil, fmt.Errorf("check base archive for changes: %w", err)
+		}
+		out[table] = changed
+	}
+	return out, nil
+}
+
+// baseRecord returns the current value of a record the base archive holds:
+// the re-synced value when it changed, otherwise the archived one. Records
+// that became unreadable are left for FetchTree to fetch and report.
+func baseRecord(arc *archive.Archive, changed map[string]map[string]map[string]any, table string, id string) (map[string]any, bool) {
+	base := arc.Base()
+	if base == nil {
+		return nil, false
+	}
+	if v, ok := changed[table][id]; ok {
+		return v, v["id"] != nil
+	}
+	if e, ok := base.Entry(table, id); !ok || e.Version <= 0 {
+		return nil, false
+	}
+	v, ok, err := base.Get(table, id)
+	return v, ok && err == nil
+}
+
+// storeBackupRecord writes value unless this archive already has it or it is
+// unchanged in the base archive. It reports "written", "reused" or "".
+func storeBackupRecord(arc *archive.Archive, table string, id string, value map[string]any) (string, error) {
+	if _, ok := arc.Entry(table, id); ok {
+		return "", nil
+	}
+	version := archive.RecordVersion(value)
+	if base := arc.Base(); base != nil && version > 0 {
+		if e, ok := base.Entry(table, id); ok && e.Version == version {
+			arc.Reuse(table, id, version)
+			return "reused", nil
+		}
+	}
+	if err := arc.Put(table, id, value); err != nil {
+		return "", err
+	}
+	return "written", nil
+}
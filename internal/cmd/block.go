@@ -13,6 +13,7 @@ import (
 type BlockCmd struct {
 	Get      BlockGetCmd      `cmd:"" help:"Fetch a block record by ID"`
 	Children BlockChildrenCmd `cmd:"" help:"Fetch one-level child blocks"`
+	Upload   BlockUploadCmd   `cmd:"" help:"Upload a local file as a new image/file/pdf block"`
 }
 
 type BlockGetCmd struct {
@@ -37,6 +38,9 @@ func (c *BlockGetCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("parse block id: %w", err)
 	}
+	if client, err = client.ForRecord(ctx, "block", id); err != nil {
+		return err
+	}
 
 	resp, err := client.SyncBlockRecords(ctx, []string{id})
 	if err != nil {
@@ -63,7 +67,7 @@ func (c *BlockGetCmd) Run(ctx context.Context) error {
 		out = map[string]any{"id": id, "object": row}
 	}
 
-	return writeJSON(c.Output, out)
+	return writeOutput(ctx, c.Output, out)
 }
 
 func (c *BlockChildrenCmd) Run(ctx context.Context) error {
@@ -76,6 +80,9 @@ func (c *BlockChildrenCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("parse block id: %w", err)
 	}
+	if client, err = client.ForRecord(ctx, "block", id); err != nil {
+		return err
+	}
 
 	resp, err := client.SyncBlockRecords(ctx, []string{id})
 	if err != nil {
@@ -88,7 +95,7 @@ func (c *BlockChildrenCmd) Run(ctx context.Context) error {
 	}
 	childIDs := extractChildIDs(parent)
 	if len(childIDs) == 0 {
-		return writeJSON(c.Output, map[string]any{"parent_id": id, "children": []any{}})
+		return writeOutput(ctx, c.Output, map[string]any{"parent_id": id, "children": []any{}})
 	}
 
 	childResp, err := client.SyncBlockRecords(ctx, childIDs)
@@ -111,12 +118,30 @@ func (c *BlockChildrenCmd) Run(ctx context.Context) error {
 		}
 	}
 
-	return writeJSON(c.Output, map[string]any{
+	return writeOutput(ctx, c.Output, map[string]any{
 		"parent_id": id,
 		"children":  children,
 	})
 }
 
+// fetchBlock reads a block as a logged-in user who can access it; callers
+// still pick their client with ForSpace from the block's space_id.
+func fetchBlock(ctx context.Context, client *notionclient.Client, id string) (map[string]any, error) {
+	client, err := client.ForRecord(ctx, "block", id)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.SyncBlockRecords(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	row := notionclient.FlattenRecordMap(resp)["block"][id]
+	if len(row) == 0 {
+		return nil, fmt.Errorf("block %s not found or not accessible", id)
+	}
+	return row, nil
+}
+
 func extractChildIDs(block map[string]any) []string {
 	arr, _ := block["content"].([]any)
 	ids := make([]string, 0, len(arr))
@@ -130,6 +155,33 @@ func extractChildIDs(block map[string]any) []string {
 	return ids
 }
 
+// writeOutput is writeJSON for command results. With --resolve-users it
+// fills in partial user objects and adds a top-level "users" map.
+func writeOutput(ctx context.Context, path string, v any) error {
+	resolver, _ := ctx.Value(userResolverContextKey{}).(*notionclient.UserResolver)
+	if resolver == nil {
+		return writeJSON(path, v)
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+	users, err := resolver.Resolve(ctx, notionclient.CollectUserIDs(doc))
+	if err != nil {
+		return err
+	}
+	notionclient.FillUserObjects(doc, users)
+	if m, ok := doc.(map[string]any); ok {
+		m["users"] = users
+	}
+	return writeJSON(path, doc)
+}
+
 func writeJSON(path string, v any) error {
 	body, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
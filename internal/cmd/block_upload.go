@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type BlockUploadCmd struct {
+	ParentID   string `arg:"" name:"parent" help:"Parent page/block ID (UUID, 32-char, or URL)"`
+	Path       string `arg:"" name:"path" help:"Local file to upload" type:"existingfile"`
+	Type       string `name:"type" enum:"auto,image,file,pdf" default:"auto" help:"Block type to create (auto picks by content type)"`
+	After      string `name:"after" help:"Insert after this sibling block ID instead of appending"`
+	MaxSizeMB  int    `name:"max-size-mb" default:"5" help:"Refuse files larger than this many MiB (0 disables the check)"`
+	NoProgress bool   `name:"no-progress" help:"Do not report upload progress on stderr"`
+	Output     string `name:"output" short:"o" help:"Write JSON output to this file instead of stdout"`
+}
+
+func (c *BlockUploadCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	parentID, err := notionclient.ParsePageID(c.ParentID)
+	if err != nil {
+		return fmt.Errorf("parse parent id: %w", err)
+	}
+	afterID := ""
+	if strings.TrimSpace(c.After) != "" {
+		afterID, err = notionclient.ParsePageID(c.After)
+		if err != nil {
+			return fmt.Errorf("parse after id: %w", err)
+		}
+	}
+
+	f, err := os.Open(c.Path)
+	if err != nil {
+		return fmt.Errorf("open upload file: %w", err)
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat upload file: %w", err)
+	}
+	size := st.Size()
+	if size == 0 {
+		return fmt.Errorf("refusing to upload empty file %s", c.Path)
+	}
+	if c.MaxSizeMB > 0 && size > int64(c.MaxSizeMB)<<20 {
+		return fmt.Errorf("file %s is %s, larger than --max-size-mb=%d", c.Path, humanBytes(size), c.MaxSizeMB)
+	}
+
+	contentType, err := detectContentType(f, c.Path)
+	if err != nil {
+		return err
+	}
+	blockType := c.Type
+	if blockType == "auto" {
+		blockType = blockTypeForContentType(contentType)
+	}
+
+	parent, err := fetchBlock(ctx, client, parentID)
+	if err != nil {
+		return fmt.Errorf("fetch parent block: %w", err)
+	}
+	spaceID, _ := parent["space_id"].(string)
+	if spaceID == "" {
+		return fmt.Errorf("parent block %s has no space_id", parentID)
+	}
+	client = client.ForSpace(ctx, spaceID)
+
+	name := filepath.Base(c.Path)
+	target, err := client.GetUploadFileURL(ctx, name, contentType, size)
+	if err != nil {
+		return fmt.Errorf("request upload url: %w", err)
+	}
+
+	var body io.Reader = f
+	if !c.NoProgress {
+		body = newProgressReader(f, "uploading "+name, size)
+	}
+	if err := client.PutUpload(ctx, target, contentType, body, size); err != nil {
+		return err
+	}
+
+	blockID, err := notionclient.NewID()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	rec := client.NewBlockRecord(blockID, blockType, parentID, "block", spaceID, now)
+	rec["properties"] = map[string]any{
+		"source": []any{[]any{target.URL}},
+		"title":  []any{[]any{name}},
+		"size":   []any{[]any{humanBytes(size)}},
+	}
+	rec["format"] = map[string]any{"display_source": target.URL}
+
+	ops := []notionclient.Operation{
+		notionclient.SetOp("block", blockID, spaceID, nil, rec),
+		notionclient.ListAfterOp("block", parentID, spaceID, []string{"content"}, blockID, afterID),
+		notionclient.UpdateOp("block", parentID, spaceID, nil, map[string]any{"last_edited_time": now}),
+	}
+	if _, err := client.SaveTransactions(ctx, spaceID, "nocli.blockUpload", ops); err != nil {
+		return fmt.Errorf("create %s block: %w", blockType, err)
+	}
+
+	return writeOutput(ctx, c.Output, map[string]any{
+		"id":           blockID,
+		"parent_id":    parentID,
+		"type":         blockType,
+		"name":         name,
+		"content_type": contentType,
+		"size":         size,
+		"url":          target.URL,
+	})
+}
+
+func detectContentType(f *os.File, path string) (string, error) {
+	if ct := mime.TypeByExtension(strings.ToLower(filepath.Ext(path))); ct != "" {
+		return ct, nil
+	}
+	head := make([]byte, 512)
+	n, err := f.Read(head)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("read upload file: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("rewind upload file: %w", err)
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+func blockTypeForContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return "image"
+	case mediaType == "application/pdf":
+		return "pdf"
+	default:
+		return "file"
+	}
+}
@@ -33,6 +33,9 @@ func (c *CollectionQueryCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("parse view id: %w", err)
 	}
+	if client, err = client.ForRecord(ctx, "collection", collectionID); err != nil {
+		return err
+	}
 
 	resp, err := client.QueryCollection(ctx, collectionID, viewID, c.Limit)
 	if err != nil {
@@ -40,7 +43,7 @@ func (c *CollectionQueryCmd) Run(ctx context.Context) error {
 	}
 
 	if !c.Flatten {
-		return writeJSON(c.Output, resp)
+		return writeOutput(ctx, c.Output, resp)
 	}
 
 	flat := notionclient.FlattenRecordMap(resp)
@@ -55,7 +58,7 @@ func (c *CollectionQueryCmd) Run(ctx context.Context) error {
 		}
 	}
 
-	return writeJSON(c.Output, map[string]any{
+	return writeOutput(ctx, c.Output, map[string]any{
 		"collection_id": collectionID,
 		"view_id":       viewID,
 		"counts":        notionclient.TableCounts(flat),
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type CommentCmd struct {
+	Add     CommentAddCmd     `cmd:"" help:"Comment on a block or page, or reply to a discussion"`
+	Resolve CommentResolveCmd `cmd:"" help:"Resolve (or reopen) a discussion thread"`
+}
+
+type CommentAddCmd struct {
+	Target     string `arg:"" optional:"" name:"block-or-page" help:"Block or page URL/ID to comment on (omit with --discussion)"`
+	Text       string `name:"text" required:"" help:"Comment text; supports **bold**, *italic*, ~~strike~~, backquoted code, [link](url) and @{mentions}"`
+	Discussion string `name:"discussion" help:"Reply to this discussion ID instead of starting a new thread"`
+	Output     string `name:"output" short:"o" help:"Write JSON output to this file instead of stdout"`
+}
+
+type CommentResolveCmd struct {
+	Discussion string `arg:"" name:"discussion-id" help:"Discussion ID (see page comments)"`
+	Reopen     bool   `name:"reopen" help:"Mark the thread as open again"`
+}
+
+func (c *CommentAddCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+	if strings.TrimSpace(c.Text) == "" {
+		return fmt.Errorf("--text is empty")
+	}
+
+	var discussionID, blockID, spaceID string
+	if strings.TrimSpace(c.Discussion) != "" {
+		id, err := notionclient.ParsePageID(c.Discussion)
+		if err != nil {
+			return fmt.Errorf("parse discussion id: %w", err)
+		}
+		if client, err = client.ForRecord(ctx, "discussion", id); err != nil {
+			return err
+		}
+		d, err := fetchDiscussion(ctx, client, id)
+		if err != nil {
+			return err
+		}
+		discussionID = id
+		blockID, _ = d["parent_id"].(string)
+		spaceID, _ = d["space_id"].(string)
+	} else {
+		if strings.TrimSpace(c.Target) == "" {
+			return fmt.Errorf("pass a block or page to comment on, or --discussion to reply")
+		}
+		id, err := notionclient.ParsePageID(c.Target)
+		if err != nil {
+			return err
+		}
+		block, err := fetchBlock(ctx, client, id)
+		if err != nil {
+			return err
+		}
+		blockID = id
+		spaceID, _ = block["space_id"].(string)
+	}
+	if spaceID == "" {
+		return fmt.Errorf("could not determine the space of %s", blockID)
+	}
+	client = client.ForSpace(ctx, spaceID)
+
+	text, err := parseCommentText(ctx, client, c.Text)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	commentID, err := notionclient.NewID()
+	if err != nil {
+		return err
+	}
+	comment := map[string]any{
+		"id":               commentID,
+		"version":          1,
+		"alive":            true,
+		"parent_id":        discussionID,
+		"parent_table":     "discussion",
+		"space_id":         spaceID,
+		"text":             text,
+		"created_time":     now,
+		"last_edited_time": now,
+	}
+	if user := client.CurrentUserID(); user != "" {
+		comment["created_by_id"] = user
+		comment["created_by_table"] = "notion_user"
+	}
+
+	ops := make([]notionclient.Operation, 0, 3)
+	reply := discussionID != ""
+	if !reply {
+		if discussionID, err = notionclient.NewID(); err != nil {
+			return err
+		}
+		comment["parent_id"] = discussionID
+		ops = append(ops,
+			notionclient.SetOp("discussion", discussionID, spaceID, nil, map[string]any{
+				"id":           discussionID,
+				"version":      1,
+				"alive":        true,
+				"parent_id":    blockID,
+				"parent_table": "block",
+				"space_id":     spaceID,
+				"resolved":     false,
+				"comments":     []string{},
+			}),
+			notionclient.ListAfterOp("block", blockID, spaceID, []string{"discussions"}, discussionID, ""),
+		)
+	}
+	ops = append(ops,
+		notionclient.SetOp("comment", commentID, spaceID, nil, comment),
+		notionclient.ListAfterOp("discussion", discussionID, spaceID, []string{"comments"}, commentID, ""),
+	)
+	if _, err := client.SaveTransactions(ctx, spaceID, "nocli.commentAdd", ops); err != nil {
+		return fmt.Errorf("save comment: %w", err)
+	}
+
+	return writeOutput(ctx, c.Output, map[string]any{
+		"comment_id":    commentID,
+		"discussion_id": discussionID,
+		"block_id":      blockID,
+		"reply":         reply,
+	})
+}
+
+func (c *CommentResolveCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	id, err := notionclient.ParsePageID(c.Discussion)
+	if err != nil {
+		return fmt.Errorf("parse discussion id: %w", err)
+	}
+	if client, err = client.ForRecord(ctx, "discussion", id); err != nil {
+		return err
+	}
+	d, err := fetchDiscussion(ctx, client, id)
+	if err != nil {
+		return err
+	}
+	spaceID, _ := d["space_id"].(string)
+	if spaceID == "" {
+		return fmt.Errorf("discussion %s has no space_id", id)
+	}
+	client = client.ForSpace(ctx, spaceID)
+
+	resolved := !c.Reopen
+	op := notionclient.UpdateOp("discussion", id, spaceID, nil, map[string]any{"resolved": resolved})
+	if _, err := client.SaveTransactions(ctx, spaceID, "nocli.commentResolve", []notionclient.Operation{op}); err != nil {
+		return fmt.Errorf("update discussion: %w", err)
+	}
+	return writeOutput(ctx, "", map[string]any{"discussion_id": id, "resolved": resolved})
+}
+
+func fetchDiscussion(ctx context.Context, client *notionclient.Client, id string) (map[string]any, error) {
+	rows, err := client.GetRecords(ctx, "discussion", []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("fetch discussion: %w", err)
+	}
+	d := rows[id]
+	if len(d) == 0 || !notionclient.IsAlive(d) {
+		return nil, fmt.Errorf("discussion %s not found or not accessible", id)
+	}
+	return d, nil
+}
+
+var commentTokenPattern = regexp.MustCompile("@\\{[^}]+\\}|\\*\\*[^*]+\\*\\*|~~[^~]+~~|\\*[^*]+\\*|`[^`]+`|\\[[^\\]]+\\]\\([^)\\s]+\\)")
+
+var commentLinkPattern = regexp.MustCompile(`^\[([^\]]+)\]\(([^)\s]+)\)$`)
+
+// parseCommentText turns lightweight markup into Notion rich text. Mentions
+// are @{email or user ID}, @{page:<url or ID>} and @{date:YYYY-MM-DD}.
+func parseCommentText(ctx context.Context, client *notionclient.Client, s string) ([]any, error) {
+	out := make([]any, 0)
+	plain := func(text string) {
+		if text != "" {
+			out = append(out, notionclient.TextSegment(text))
+		}
+	}
+
+	last := 0
+	for _, loc := range commentTokenPattern.FindAllStringIndex(s, -1) {
+		plain(s[last:loc[0]])
+		last = loc[1]
+		tok := s[loc[0]:loc[1]]
+		switch {
+		case strings.HasPrefix(tok, "@{"):
+			seg, err := mentionSegment(ctx, client, strings.TrimSpace(tok[2:len(tok)-1]))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, seg)
+		case strings.HasPrefix(tok, "**"):
+			out = append(out, notionclient.TextSegment(tok[2:len(tok)-2], []any{"b"}))
+		case strings.HasPrefix(tok, "~~"):
+			out = append(out, notionclient.TextSegment(tok[2:len(tok)-2], []any{"s"}))
+		case strings.HasPrefix(tok, "*"):
+			out = append(out, notionclient.TextSegment(tok[1:len(tok)-1], []any{"i"}))
+		case strings.HasPrefix(tok, "`"):
+			out = append(out, notionclient.TextSegment(tok[1:len(tok)-1], []any{"c"}))
+		default:
+			m := commentLinkPattern.FindStringSubmatch(tok)
+			out = append(out, notionclient.TextSegment(m[1], []any{"a", m[2]}))
+		}
+	}
+	plain(s[last:])
+	return out, nil
+}
+
+func mentionSegment(ctx context.Context, client *notionclient.Client, ref string) ([]any, error) {
+	if page, ok := strings.CutPrefix(ref, "page:"); ok {
+		id, err := notionclient.ParsePageID(page)
+		if err != nil {
+			return nil, fmt.Errorf("mention %q: %w", ref, err)
+		}
+		return notionclient.PageMention(id), nil
+	}
+	if date, ok := strings.CutPrefix(ref, "date:"); ok {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("mention %q: want date:YYYY-MM-DD", ref)
+		}
+		return notionclient.DateMention(date), nil
+	}
+
+	ref = strings.TrimPrefix(ref, "user:")
+	id, err := client.ResolveUser(ctx, ref)
+	if err != nil && strings.Contains(ref, "@") {
+		id, err = client.FindUser(ctx, ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mention %q: %w", ref, err)
+	}
+	return notionclient.UserMention(id), nil
+}
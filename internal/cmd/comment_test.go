@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+func TestParseCommentText(t *testing.T) {
+	const userID = "11111111-1111-4111-8111-111111111111"
+	const pageID = "22222222-2222-4222-8222-222222222222"
+	tests := []struct {
+		in   string
+		want []any
+	}{
+		{"plain", []any{[]any{"plain"}}},
+		{"a **bold** b", []any{[]any{"a "}, []any{"bold", []any{[]any{"b"}}}, []any{" b"}}},
+		{"*it*", []any{[]any{"it", []any{[]any{"i"}}}}},
+		{"~~gone~~!", []any{[]any{"gone", []any{[]any{"s"}}}, []any{"!"}}},
+		{"run `go test`", []any{[]any{"run "}, []any{"go test", []any{[]any{"c"}}}}},
+		{"[docs](https://example.com/a)", []any{[]any{"docs", []any{[]any{"a", "https://example.com/a"}}}}},
+		{"hi @{" + userID + "}", []any{[]any{"hi "}, notionclient.UserMention(userID)}},
+		{"@{user:" + userID + "}", []any{notionclient.UserMention(userID)}},
+		{"see @{page:https://www.notion.so/Plan-22222222222242228222222222222222}", []any{[]any{"see "}, notionclient.PageMention(pageID)}},
+		{"due @{date:2026-01-31}", []any{[]any{"due "}, notionclient.DateMention("2026-01-31")}},
+		{"**a** and *b*", []any{[]any{"a", []any{[]any{"b"}}}, []any{" and "}, []any{"b", []any{[]any{"i"}}}}},
+		{"[no link]", []any{[]any{"[no link]"}}},
+	}
+	client, err := notionclient.New(notionclient.Options{TokenV2: "tok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		got, err := parseCommentText(context.Background(), client, tt.in)
+		if err != nil {
+			t.Errorf("parseCommentText(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCommentText(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCommentTextBadMention(t *testing.T) {
+	client, err := notionclient.New(notionclient.Options{TokenV2: "tok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range []string{"@{date:31.01.2026}", "@{page:nope}", "@{not-an-id}"} {
+		if _, err := parseCommentText(context.Background(), client, in); err == nil {
+			t.Errorf("parseCommentText(%q): no error", in)
+		}
+	}
+}
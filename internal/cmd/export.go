@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type ExportCmd struct {
+	Native ExportNativeCmd `cmd:"" help:"Run Notion's built-in export (HTML/Markdown/PDF zip) and download the archive"`
+}
+
+type ExportNativeCmd struct {
+	URLOrID      string        `arg:"" help:"Notion page URL or page ID" name:"url_or_id"`
+	Format       string        `name:"format" enum:"markdown,html,pdf" default:"markdown" help:"Export format"`
+	Recursive    bool          `name:"recursive" help:"Include subpages"`
+	NoFiles      bool          `name:"no-files" help:"Skip attached files and images in the archive"`
+	TimeZone     string        `name:"time-zone" default:"UTC" help:"IANA time zone used for dates in the export"`
+	Locale       string        `name:"locale" default:"en" help:"Locale used for the export"`
+	Out          string        `name:"out" required:"" help:"Path of the downloaded zip archive"`
+	PollTimeout  time.Duration `name:"poll-timeout" default:"30m" help:"Give up if the export task has not finished after this long"`
+	PollInterval time.Duration `name:"poll-interval" default:"1s" help:"Initial delay between task status polls (backs off up to 15s)"`
+}
+
+func (c *ExportNativeCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	pageID, err := notionclient.ParsePageID(c.URLOrID)
+	if err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(c.TimeZone); err != nil {
+		return fmt.Errorf("invalid --time-zone: %w", err)
+	}
+
+	block, err := fetchBlock(ctx, client, pageID)
+	if err != nil {
+		return fmt.Errorf("fetch page for export: %w", err)
+	}
+	spaceID, _ := block["space_id"].(string)
+	client = client.ForSpace(ctx, spaceID)
+
+	includeContents := "everything"
+	if c.NoFiles {
+		includeContents = "no_files"
+	}
+	taskID, err := client.EnqueueExportBlock(ctx, pageID, spaceID, c.Recursive, notionclient.ExportOptions{
+		ExportType:      c.Format,
+		TimeZone:        c.TimeZone,
+		Locale:          c.Locale,
+		IncludeContents: includeContents,
+	})
+	if err != nil {
+		return fmt.Errorf("enqueue export: %w", err)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "export task %s enqueued\n", taskID)
+
+	task, err := waitForTask(ctx, client, taskID, c.PollInterval, c.PollTimeout)
+	if err != nil {
+		return err
+	}
+
+	tmp := c.Out + ".part"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	n, err := client.Download(ctx, task.ExportURL, f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("close output file: %w", cerr)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, c.Out); err != nil {
+		return fmt.Errorf("commit output file: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "downloaded %s (%s, %d pages)\n", c.Out, humanBytes(n), task.PagesExported)
+	return nil
+}
+
+func waitForTask(ctx context.Context, client *notionclient.Client, taskID string, interval time.Duration, timeout time.Duration) (notionclient.Task, error) {
+	const maxInterval = 15 * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	lastPages := -1
+
+	for {
+		task, err := client.GetTask(ctx, taskID)
+		if err != nil {
+			return notionclient.Task{}, fmt.Errorf("poll export task: %w", err)
+		}
+		switch task.State {
+		case "success":
+			if task.ExportURL == "" {
+				return task, fmt.Errorf("export task %s succeeded without an export URL", taskID)
+			}
+			return task, nil
+		case "failure":
+			return task, fmt.Errorf("export task %s failed: %s", taskID, task.Error)
+		}
+
+		if task.PagesExported != lastPages {
+			lastPages = task.PagesExported
+			_, _ = fmt.Fprintf(os.Stderr, "export %s: %d pages exported\n", task.State, task.PagesExported)
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return task, fmt.Errorf("export task %s did not finish within %s", taskID, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return task, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = interval * 3 / 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jodok/nocli/internal/notionclient"
+	"github.com/jodok/nocli/internal/pagegraph"
+)
+
+type GraphCmd struct {
+	Root     string `arg:"" help:"Root page URL or page ID" name:"root"`
+	Depth    int    `name:"depth" default:"0" help:"Levels of subpages to crawl below the root (0 = unlimited)"`
+	Format   string `name:"format" enum:"dot,graphml,json" default:"dot" help:"Output format"`
+	RowLimit int    `name:"row-limit" default:"1000" help:"Maximum rows fetched per database"`
+	Output   string `name:"output" short:"o" help:"Write output to this file instead of stdout"`
+}
+
+func (c *GraphCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+	if c.Depth < 0 {
+		return fmt.Errorf("--depth must be >= 0")
+	}
+
+	rootID, err := notionclient.ParsePageID(c.Root)
+	if err != nil {
+		return err
+	}
+	root, err := fetchBlock(ctx, client, rootID)
+	if err != nil {
+		return err
+	}
+	if spaceID, _ := root["space_id"].(string); spaceID != "" {
+		client = client.ForSpace(ctx, spaceID)
+	}
+
+	tree, err := client.FetchTree(ctx, []string{rootID}, notionclient.TreeOptions{
+		MaxDepth:           c.Depth,
+		IncludeCollections: true,
+		RowLimit:           c.RowLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("fetch page tree: %w", err)
+	}
+
+	g := pagegraph.Build(tree)
+	external := make([]string, 0)
+	for _, n := range g.Nodes {
+		if n.Kind == pagegraph.KindExternal {
+			external = append(external, n.ID)
+		}
+	}
+	titles, err := client.GetRecords(ctx, "block", external)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: could not fetch titles of linked pages: %v\n", err)
+	}
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.Kind == pagegraph.KindExternal {
+			if block := titles[n.ID]; block != nil && notionclient.IsAlive(block) {
+				n.Title = notionclient.BlockTitle(block)
+			}
+		}
+		n.URL = client.PageURL(n.ID)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "graph: %d pages, %d edges\n", len(g.Nodes), len(g.Edges))
+
+	var buf bytes.Buffer
+	switch c.Format {
+	case "json":
+		return writeOutput(ctx, c.Output, g)
+	case "graphml":
+		err = pagegraph.WriteGraphML(&buf, g)
+	default:
+		err = pagegraph.WriteDOT(&buf, g)
+	}
+	if err != nil {
+		return err
+	}
+	if c.Output != "" {
+		if err := os.WriteFile(c.Output, buf.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		return nil
+	}
+	_, err = os.Stdout.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jodok/nocli/internal/notionclient"
+	"github.com/jodok/nocli/internal/pagegraph"
+)
+
+type LintCmd struct {
+	Links LintLinksCmd `cmd:"" help:"Report links, mentions and relations pointing at archived or inaccessible pages"`
+}
+
+type LintLinksCmd struct {
+	Root          string        `arg:"" help:"Root page URL or page ID" name:"root"`
+	Depth         int           `name:"depth" default:"0" help:"Levels of subpages to check below the root (0 = unlimited)"`
+	RowLimit      int           `name:"row-limit" default:"1000" help:"Maximum rows fetched per database"`
+	CheckExternal bool          `name:"check-external" help:"Also HEAD-check bookmark, embed and link preview URLs"`
+	Concurrency   int           `name:"concurrency" default:"8" help:"Parallel requests for --check-external"`
+	Timeout       time.Duration `name:"timeout" default:"10s" help:"Timeout per external URL check"`
+	Format        string        `name:"format" enum:"json,text" default:"json" help:"Output format"`
+	FailOnIssues  bool          `name:"fail-on-issues" help:"Exit with an error when any issue is found"`
+	Output        string        `name:"output" short:"o" help:"Write JSON output to this file instead of stdout"`
+}
+
+// Problems reported by lint links.
+const (
+	lintArchived     = "archived"
+	lintInaccessible = "inaccessible"
+	lintHTTPError    = "http_error"
+	lintUnreachable  = "unreachable"
+)
+
+type lintIssue struct {
+	Problem   string `json:"problem"`
+	Kind      string `json:"kind"`
+	PageID    string `json:"page_id"`
+	PageTitle string `json:"page_title"`
+	BlockID   string `json:"block_id"`
+	Property  string `json:"property,omitempty"`
+	Target    string `json:"target"`
+	// Status is the HTTP status of an external URL check.
+	Status int    `json:"status,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type lintReport struct {
+	Root         string      `json:"root"`
+	Pages        int         `json:"pages"`
+	References   int         `json:"references"`
+	ExternalURLs int         `json:"external_urls"`
+	Issues       []lintIssue `json:"issues"`
+}
+
+// lintExternalTypes maps block types with an external URL to the property
+// holding it.
+var lintExternalTypes = map[string]string{
+	"bookmark":     "link",
+	"embed":        "source",
+	"link_preview": "source",
+}
+
+func (c *LintLinksCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+	if c.Depth < 0 {
+		return fmt.Errorf("--depth must be >= 0")
+	}
+	if c.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be >= 1")
+	}
+
+	rootID, err := notionclient.ParsePageID(c.Root)
+	if err != nil {
+		return err
+	}
+	root, err := fetchBlock(ctx, client, rootID)
+	if err != nil {
+		return err
+	}
+	if spaceID, _ := root["space_id"].(string); spaceID != "" {
+		client = client.ForSpace(ctx, spaceID)
+	}
+
+	tree, err := client.FetchTree(ctx, []string{rootID}, notionclient.TreeOptions{
+		MaxDepth:           c.Depth,
+		IncludeCollections: true,
+		RowLimit:           c.RowLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("fetch page tree: %w", err)
+	}
+
+	report := lintReport{Root: rootID, Issues: []lintIssue{}}
+	refs := make([]lintIssue, 0)
+	urls := make([]lintIssue, 0)
+	pageTitle := func(id string) string {
+		return titleOr(notionclient.BlockTitle(tree.Block(id)))
+	}
+	pagegraph.Walk(tree, func(owner string, id string, block map[string]any, row bool) {
+		if owner == id {
+			report.Pages++
+		}
+		for _, ref := range pagegraph.BlockReferences(tree.Records, block) {
+			issue := lintIssue{
+				Kind:      ref.Type,
+				PageID:    owner,
+				PageTitle: pageTitle(owner),
+				BlockID:   id,
+				Target:    ref.Target,
+			}
+			if ref.Type == pagegraph.Link {
+				issue.Kind = "link_to_page"
+			}
+			if row {
+				issue.Property = rowPropertyName(tree, id, ref.Property)
+			}
+			refs = append(refs, issue)
+		}
+		typ, _ := block["type"].(string)
+		if prop, ok := lintExternalTypes[typ]; ok {
+			props, _ := block["properties"].(map[string]any)
+			if u := strings.TrimSpace(notionclient.PlainText(props[prop])); strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+				urls = append(urls, lintIssue{Kind: typ, PageID: owner, PageTitle: pageTitle(owner), BlockID: id, Target: u})
+			}
+		}
+	})
+	report.References = len(refs)
+
+	targets, err := c.lookupTargets(ctx, client, tree, refs)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		target, ok := targets[ref.Target]
+		switch {
+		case !ok || len(target) == 0:
+			ref.Problem = lintInaccessible
+		case !notionclient.IsAlive(target):
+			ref.Problem = lintArchived
+			ref.Detail = titleOr(notionclient.BlockTitle(target))
+		default:
+			continue
+		}
+		report.Issues = append(report.Issues, ref)
+	}
+
+	if c.CheckExternal {
+		report.ExternalURLs = len(urls)
+		report.Issues = append(report.Issues, c.checkURLs(ctx, urls)...)
+	}
+
+	if c.Format == "text" && c.Output == "" {
+		printLintReport(report)
+	} else if err := writeOutput(ctx, c.Output, report); err != nil {
+		return err
+	}
+	if c.FailOnIssues && len(report.Issues) > 0 {
+		return fmt.Errorf("%d link issue(s) found", len(report.Issues))
+	}
+	return nil
+}
+
+// lookupTargets returns the records referenced by refs, taking them from
+// the tree when present and fetching the rest. Targets Notion does not
+// return are absent from the result.
+func (c *LintLinksCmd) lookupTargets(ctx context.Context, client *notionclient.Client, tree *notionclient.Tree, refs []lintIssue) (map[string]map[string]any, error) {
+	out := map[string]map[string]any{}
+	missing := make([]string, 0)
+	for _, ref := range refs {
+		if _, ok := out[ref.Target]; ok {
+			continue
+		}
+		if block := tree.Block(ref.Target); block != nil {
+			out[ref.Target] = block
+			continue
+		}
+		out[ref.Target] = nil
+		missing = append(missing, ref.Target)
+	}
+	fetched, err := client.GetRecords(ctx, "block", missing)
+	if err != nil {
+		return nil, fmt.Errorf("fetch link targets: %w", err)
+	}
+	for _, id := range missing {
+		// Records the user cannot read come back as {"role": "none"}.
+		if block := fetched[id]; block["id"] != nil {
+			out[id] = block
+		} else {
+			delete(out, id)
+		}
+	}
+	return out, nil
+}
+
+// checkURLs HEAD-checks each distinct URL once, at most c.Concurrency at a
+// time, and returns an issue for every block whose URL failed.
+func (c *LintLinksCmd) checkURLs(ctx context.Context, blocks []lintIssue) []lintIssue {
+	httpClient := &http.Client{Timeout: c.Timeout}
+	results := map[string]lintIssue{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, c.Concurrency)
+
+	for _, b := range blocks {
+		mu.Lock()
+		_, started := results[b.Target]
+		if !started {
+			results[b.Target] = lintIssue{}
+		}
+		mu.Unlock()
+		if started {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(u string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res := checkURL(ctx, httpClient, u)
+			mu.Lock()
+			results[u] = res
+			mu.Unlock()
+		}(b.Target)
+	}
+	wg.Wait()
+
+	out := make([]lintIssue, 0)
+	for _, b := range blocks {
+		res := results[b.Target]
+		if res.Problem == "" {
+			continue
+		}
+		b.Problem, b.Status, b.Detail = res.Problem, res.Status, res.Detail
+		out = append(out, b)
+	}
+	return out
+}
+
+// checkURL sends a HEAD request, retrying with GET when the server does not
+// allow HEAD. Only the Problem, Status and Detail fields of the result are
+// set.
+func checkURL(ctx context.Context, client *http.Client, u string) lintIssue {
+	status, err := requestStatus(ctx, client, http.MethodHead, u)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = requestStatus(ctx, client, http.MethodGet, u)
+	}
+	switch {
+	case err != nil:
+		return lintIssue{Problem: lintUnreachable, Detail: err.Error()}
+	case status >= 400:
+		return lintIssue{Problem: lintHTTPError, Status: status, Detail: http.StatusText(status)}
+	}
+	return lintIssue{}
+}
+
+func requestStatus(ctx context.Context, client *http.Client, method string, u string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "nocli-lint")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// rowPropertyName maps a row's property key to its schema name.
+func rowPropertyName(tree *notionclient.Tree, rowID string, key string) string {
+	row := tree.Block(rowID)
+	cid, _ := row["parent_id"].(string)
+	schema, _ := tree.Records["collection"][cid]["schema"].(map[string]any)
+	prop, _ := schema[key].(map[string]any)
+	if name, _ := prop["name"].(string); name != "" {
+		return name
+	}
+	return key
+}
+
+func printLintReport(r lintReport) {
+	for _, is := range r.Issues {
+		where := is.Kind
+		if is.Property != "" {
+			where += " " + is.Property
+		}
+		problem := is.Problem
+		if is.Status != 0 {
+			problem = fmt.Sprintf("HTTP %d", is.Status)
+		}
+		fmt.Printf("%s: %s in %q (block %s) -> %s\n", problem, where, is.PageTitle, is.BlockID, is.Target)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "checked %d pages, %d references, %d external URLs: %d issue(s)\n", r.Pages, r.References, r.ExternalURLs, len(r.Issues))
+}
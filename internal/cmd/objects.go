@@ -12,10 +12,36 @@ func (c *ObjectsCmd) Run() error {
 	fmt.Println("  nocli page objects <page> --table block --notion-block-like")
 	fmt.Println("                                            # Notion-like block objects")
 	fmt.Println("  nocli page types <page-url-or-id>         # Seen block types vs public API types")
+	fmt.Println("  nocli page history show <page> --at 2024-03-31")
+	fmt.Println("                                            # Page as it was on a date")
+	fmt.Println("  nocli page diff <page> --from latest --to live")
+	fmt.Println("                                            # Review edits block by block")
+	fmt.Println("  nocli page comments <page> --unresolved  # Open comment threads as Markdown")
+	fmt.Println("  nocli page backlinks <page>              # Pages that mention or link to a page")
+	fmt.Println("  nocli page export <page> --out ./site    # Static HTML export with assets")
 	fmt.Println("  nocli block get <block-id> --notion-block-like")
 	fmt.Println("                                            # Single block as normalized object")
 	fmt.Println("  nocli block children <block-id>           # Direct child block objects")
+	fmt.Println("  nocli block upload <parent-id> <file> [--type image|file|pdf]")
+	fmt.Println("                                            # Upload a file into a new block")
 	fmt.Println("  nocli collection query <collection-id> <view-id> --flatten")
 	fmt.Println("                                            # Collection/view object rows")
+	fmt.Println("  nocli export native <page> --format markdown --recursive --out backup.zip")
+	fmt.Println("                                            # Notion's own export archive")
+	fmt.Println("  nocli space list                          # Workspaces with IDs")
+	fmt.Println("  nocli space pages <space-id>              # Top-level sidebar pages")
+	fmt.Println("  nocli search 'quarterly plan' --type page --json")
+	fmt.Println("                                            # Find pages by title/content")
+	fmt.Println("  nocli graph <page> --depth 2 | dot -Tsvg > wiki.svg")
+	fmt.Println("                                            # Page link graph (DOT/GraphML/JSON)")
+	fmt.Println("  nocli lint links <page> --check-external --fail-on-issues")
+	fmt.Println("                                            # Dead links, mentions and relations")
+	fmt.Println("  nocli watch <database> --interval 1m      # NDJSON change events (e.g. status changes)")
+	fmt.Println("  nocli watch <page> --state w.json --webhook http://localhost:8080/hook")
+	fmt.Println("                                            # Signed webhook per change, resumable")
+	fmt.Println("  nocli site build <page> --generator hugo --out ./content")
+	fmt.Println("                                            # Markdown docs site, rebuilds changed pages")
+	fmt.Println("  nocli api loadPageChunk --data @payload.json --flatten")
+	fmt.Println("                                            # Any /api/v3/ endpoint, raw")
 	return nil
 }
@@ -2,17 +2,20 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/jodok/nocli/internal/notionclient"
 )
 
 type PageCmd struct {
-	Fetch   PageFetchCmd   `cmd:"" help:"Fetch a page via Notion private endpoints"`
-	Objects PageObjectsCmd `cmd:"" help:"Expose flattened objects from a page recordMap"`
-	Types   PageTypesCmd   `cmd:"" help:"List block types seen in page vs official Notion API block types"`
+	Fetch     PageFetchCmd     `cmd:"" help:"Fetch a page via Notion private endpoints"`
+	Objects   PageObjectsCmd   `cmd:"" help:"Expose flattened objects from a page recordMap"`
+	Types     PageTypesCmd     `cmd:"" help:"List block types seen in page vs official Notion API block types"`
+	History   PageHistoryCmd   `cmd:"" help:"List a page's version history or show a past snapshot"`
+	Diff      PageDiffCmd      `cmd:"" help:"Compare two states of a page block by block"`
+	Comments  PageCommentsCmd  `cmd:"" help:"Show a page's comment threads with their anchors"`
+	Backlinks PageBacklinksCmd `cmd:"" help:"List the pages that mention or link to a page"`
+	Export    PageExportCmd    `cmd:"" help:"Render a page (and optionally its subpages) to HTML with local assets"`
 }
 
 type PageFetchCmd struct {
@@ -31,6 +34,9 @@ func (c *PageFetchCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if client, err = client.ForRecord(ctx, "block", pageID); err != nil {
+		return err
+	}
 
 	var resp map[string]any
 	switch c.Endpoint {
@@ -48,19 +54,5 @@ func (c *PageFetchCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("fetch page %s via %s: %w", pageID, c.Endpoint, err)
 	}
 
-	body, err := json.MarshalIndent(resp, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal response json: %w", err)
-	}
-	body = append(body, '\n')
-
-	if c.Output != "" {
-		if err := os.WriteFile(c.Output, body, 0o600); err != nil {
-			return fmt.Errorf("write output file: %w", err)
-		}
-		return nil
-	}
-
-	_, err = os.Stdout.Write(body)
-	return err
+	return writeOutput(ctx, c.Output, resp)
 }
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type PageBacklinksCmd struct {
+	URLOrID string `arg:"" help:"Notion page URL or page ID" name:"url_or_id"`
+	JSON    bool   `name:"json" help:"Emit backlinks as JSON"`
+	Output  string `name:"output" short:"o" help:"Write JSON output to this file instead of stdout (implies --json)"`
+}
+
+type backlinkRef struct {
+	PageID     string   `json:"page_id"`
+	PageTitle  string   `json:"page_title"`
+	URL        string   `json:"url"`
+	Breadcrumb []string `json:"breadcrumb"`
+	BlockID    string   `json:"block_id"`
+	BlockType  string   `json:"block_type"`
+	Kind       string   `json:"kind,omitempty"`
+	Property   string   `json:"property,omitempty"`
+	Snippet    string   `json:"snippet"`
+}
+
+// snippetRadius is how many characters of context are kept on each side of
+// a mention.
+const snippetRadius = 60
+
+func (c *PageBacklinksCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	pageID, err := notionclient.ParsePageID(c.URLOrID)
+	if err != nil {
+		return err
+	}
+	page, err := fetchBlock(ctx, client, pageID)
+	if err != nil {
+		return err
+	}
+	spaceID, _ := page["space_id"].(string)
+	client = client.ForSpace(ctx, spaceID)
+	targetTitle := titleOr(notionclient.BlockTitle(page))
+
+	links, resp, err := client.GetBacklinks(ctx, pageID, spaceID)
+	if err != nil {
+		return fmt.Errorf("fetch backlinks: %w", err)
+	}
+	crumbs := newBreadcrumbs(client.ForSpace(ctx, spaceID))
+	crumbs.add(notionclient.FlattenRecordMap(resp))
+
+	refs := make([]backlinkRef, 0, len(links))
+	for _, bl := range links {
+		block, err := crumbs.record(ctx, "block", bl.BlockID)
+		if err != nil {
+			return err
+		}
+		if block == nil || !notionclient.IsAlive(block) {
+			continue
+		}
+		refPageID, refPage, err := crumbs.enclosingPage(ctx, bl.BlockID, block)
+		if err != nil {
+			return err
+		}
+		path, err := crumbs.path(ctx, refPage)
+		if err != nil {
+			return err
+		}
+
+		props, _ := block["properties"].(map[string]any)
+		textKey := "title"
+		if bl.PropertyID != "" {
+			textKey = bl.PropertyID
+		}
+		typ, _ := block["type"].(string)
+		ref := backlinkRef{
+			PageID:     refPageID,
+			PageTitle:  titleOr(notionclient.BlockTitle(refPage)),
+			URL:        client.PageURL(refPageID),
+			Breadcrumb: path,
+			BlockID:    bl.BlockID,
+			BlockType:  typ,
+			Kind:       bl.Kind,
+			Snippet:    mentionSnippet(props[textKey], pageID, targetTitle),
+		}
+		if bl.PropertyID != "" {
+			ref.Property = crumbs.propertyName(ctx, block, bl.PropertyID)
+		}
+		refs = append(refs, ref)
+	}
+
+	if c.JSON || c.Output != "" {
+		return writeOutput(ctx, c.Output, map[string]any{"page_id": pageID, "backlinks": refs})
+	}
+	if len(refs) == 0 {
+		fmt.Println("no backlinks")
+		return nil
+	}
+	for _, r := range refs {
+		fmt.Printf("%s  %s\n", r.PageTitle, r.URL)
+		if len(r.Breadcrumb) > 0 {
+			fmt.Printf("  in %s\n", strings.Join(r.Breadcrumb, " / "))
+		}
+		where := r.BlockType + " block"
+		if r.Property != "" {
+			where = "property " + r.Property
+		}
+		fmt.Printf("  %s: %s\n", where, r.Snippet)
+	}
+	return nil
+}
+
+// enclosingPage walks up from a block to the nearest page block (the block
+// itself when it is a page or database row).
+func (b *breadcrumbs) enclosingPage(ctx context.Context, id string, block map[string]any) (string, map[string]any, error) {
+	cur, curID := block, id
+	for depth := 0; depth < 64; depth++ {
+		if notionclient.IsPageBlock(cur) {
+			return curID, cur, nil
+		}
+		parentID, _ := cur["parent_id"].(string)
+		if parentTable, _ := cur["parent_table"].(string); parentTable != "block" || parentID == "" {
+			break
+		}
+		parent, err := b.record(ctx, "block", parentID)
+		if err != nil {
+			return "", nil, err
+		}
+		if parent == nil {
+			break
+		}
+		cur, curID = parent, parentID
+	}
+	return curID, cur, nil
+}
+
+// propertyName maps a row's property ID to its schema name.
+func (b *breadcrumbs) propertyName(ctx context.Context, row map[string]any, propertyID string) string {
+	collectionID, _ := row["parent_id"].(string)
+	if pt, _ := row["parent_table"].(string); pt != "collection" {
+		return propertyID
+	}
+	collection, err := b.record(ctx, "collection", collectionID)
+	if err != nil || collection == nil {
+		return propertyID
+	}
+	schema, _ := collection["schema"].(map[string]any)
+	prop, _ := schema[propertyID].(map[string]any)
+	if name, _ := prop["name"].(string); name != "" {
+		return name
+	}
+	return propertyID
+}
+
+// mentionSnippet returns the plain text around the first mention of or link
+// to targetID in a rich text value. Page mentions are shown as [[title]].
+func mentionSnippet(v any, targetID string, targetTitle string) string {
+	compact := strings.ReplaceAll(targetID, "-", "")
+	segments, _ := v.([]any)
+	var before, after strings.Builder
+	found := false
+	for _, seg := range segments {
+		parts, _ := seg.([]any)
+		if len(parts) == 0 {
+			continue
+		}
+		text, _ := parts[0].(string)
+		if !found && len(parts) > 1 && referencesBlock(parts[1], targetID, compact) {
+			found = true
+			if text == "‣" {
+				text = targetTitle
+			}
+			before.WriteString("[[" + text + "]]")
+			continue
+		}
+		if found {
+			after.WriteString(text)
+		} else {
+			before.WriteString(text)
+		}
+	}
+
+	head := []rune(before.String())
+	tail := []rune(after.String())
+	if !found {
+		return truncateRunes(head, 2*snippetRadius)
+	}
+	out := string(tail)
+	if len(tail) > snippetRadius {
+		out = string(tail[:snippetRadius]) + "…"
+	}
+	start := ""
+	if len(head) > snippetRadius+8 {
+		head = head[len(head)-snippetRadius-8:]
+		start = "…"
+	}
+	return strings.TrimSpace(start + string(head) + out)
+}
+
+func referencesBlock(decorations any, id string, compact string) bool {
+	if hasDecoration(decorations, "p", id) {
+		return true
+	}
+	decs, _ := decorations.([]any)
+	for _, d := range decs {
+		parts, _ := d.([]any)
+		if len(parts) >= 2 {
+			if tag, _ := parts[0].(string); tag == "a" {
+				url, _ := parts[1].(string)
+				if strings.Contains(strings.ReplaceAll(url, "-", ""), compact) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func truncateRunes(r []rune, n int) string {
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n]) + "…"
+}
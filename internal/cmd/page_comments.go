@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type PageCommentsCmd struct {
+	URLOrID    string `arg:"" help:"Notion page URL or page ID" name:"url_or_id"`
+	Format     string `name:"format" enum:"markdown,json" default:"markdown" help:"Output format"`
+	Unresolved bool   `name:"unresolved" help:"Only show open threads"`
+	Output     string `name:"output" short:"o" help:"Write output to this file instead of stdout"`
+}
+
+type commentThread struct {
+	ID        string          `json:"id"`
+	Resolved  bool            `json:"resolved"`
+	BlockID   string          `json:"block_id"`
+	BlockType string          `json:"block_type"`
+	PageLevel bool            `json:"page_level"`
+	BlockText string          `json:"block_text,omitempty"`
+	Anchor    *commentAnchor  `json:"anchor,omitempty"`
+	Comments  []threadComment `json:"comments"`
+}
+
+type commentAnchor struct {
+	Text string `json:"text"`
+	// Start and End are rune offsets into the block's plain-text title.
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type threadComment struct {
+	ID             string             `json:"id"`
+	Author         *notionclient.User `json:"author,omitempty"`
+	Text           string             `json:"text"`
+	CreatedTime    string             `json:"created_time,omitempty"`
+	LastEditedTime string             `json:"last_edited_time,omitempty"`
+}
+
+func (c *PageCommentsCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	pageID, err := notionclient.ParsePageID(c.URLOrID)
+	if err != nil {
+		return err
+	}
+	if client, err = client.ForRecord(ctx, "block", pageID); err != nil {
+		return err
+	}
+
+	tree, err := client.FetchTree(ctx, []string{pageID}, notionclient.TreeOptions{RootOnly: true})
+	if err != nil {
+		return fmt.Errorf("fetch page: %w", err)
+	}
+	page := tree.Block(pageID)
+	if page == nil {
+		return fmt.Errorf("page %s not found or not accessible", pageID)
+	}
+
+	threads, err := loadCommentThreads(ctx, client, tree, c.Unresolved)
+	if err != nil {
+		return err
+	}
+
+	title := titleOr(notionclient.BlockTitle(page))
+	if c.Format == "json" {
+		return writeOutput(ctx, c.Output, map[string]any{"page_id": pageID, "title": title, "threads": threads})
+	}
+
+	md := renderCommentsMarkdown(title, client.PageURL(pageID), threads)
+	if c.Output != "" {
+		if err := os.WriteFile(c.Output, []byte(md), 0o600); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		return nil
+	}
+	_, err = os.Stdout.WriteString(md)
+	return err
+}
+
+// loadCommentThreads collects the discussions on blocks of the tree's root
+// page (not its subpages) in document order, with their comments.
+func loadCommentThreads(ctx context.Context, client *notionclient.Client, tree *notionclient.Tree, unresolvedOnly bool) ([]commentThread, error) {
+	blockOf := map[string]string{}
+	discussionIDs := make([]string, 0)
+	for _, blockID := range pageBlockOrder(tree) {
+		for _, id := range notionclient.StringList(tree.Block(blockID)["discussions"]) {
+			if _, seen := blockOf[id]; !seen {
+				blockOf[id] = blockID
+				discussionIDs = append(discussionIDs, id)
+			}
+		}
+	}
+
+	discussions, err := client.GetRecords(ctx, "discussion", discussionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("fetch discussions: %w", err)
+	}
+	commentIDs := make([]string, 0)
+	for _, id := range discussionIDs {
+		commentIDs = append(commentIDs, notionclient.StringList(discussions[id]["comments"])...)
+	}
+	comments, err := client.GetRecords(ctx, "comment", commentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("fetch comments: %w", err)
+	}
+
+	authorIDs := make([]string, 0)
+	for _, cm := range comments {
+		if id, _ := cm["created_by_id"].(string); id != "" {
+			authorIDs = append(authorIDs, id)
+		}
+		authorIDs = append(authorIDs, notionclient.CollectUserIDs(cm["text"])...)
+	}
+	users, err := notionclient.NewUserResolver(client).Resolve(ctx, uniqueStrings(authorIDs))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: could not resolve comment authors: %v\n", err)
+		users = map[string]notionclient.User{}
+	}
+
+	threads := make([]commentThread, 0, len(discussionIDs))
+	for _, id := range discussionIDs {
+		d := discussions[id]
+		if d == nil || !notionclient.IsAlive(d) {
+			continue
+		}
+		resolved, _ := d["resolved"].(bool)
+		if unresolvedOnly && resolved {
+			continue
+		}
+		block := tree.Block(blockOf[id])
+		blockType, _ := block["type"].(string)
+		t := commentThread{
+			ID:        id,
+			Resolved:  resolved,
+			BlockID:   blockOf[id],
+			BlockType: blockType,
+			PageLevel: blockOf[id] == tree.Roots[0],
+			BlockText: notionclient.BlockTitle(block),
+			Anchor:    discussionAnchor(block, id),
+			Comments:  []threadComment{},
+		}
+		if t.PageLevel {
+			t.BlockText = ""
+		}
+		if t.Anchor == nil {
+			if text := notionclient.PlainText(d["context"]); text != "" {
+				t.Anchor = &commentAnchor{Text: text, Start: -1, End: -1}
+			}
+		}
+		for _, cid := range notionclient.StringList(d["comments"]) {
+			cm := comments[cid]
+			if cm == nil || !notionclient.IsAlive(cm) {
+				continue
+			}
+			tc := threadComment{
+				ID:             cid,
+				Text:           richTextWithUsers(cm["text"], users),
+				CreatedTime:    notionclient.MillisToISO8601(cm["created_time"]),
+				LastEditedTime: notionclient.MillisToISO8601(cm["last_edited_time"]),
+			}
+			if authorID, _ := cm["created_by_id"].(string); authorID != "" {
+				u, ok := users[authorID]
+				if !ok {
+					u = notionclient.User{ID: authorID}
+				}
+				tc.Author = &u
+			}
+			t.Comments = append(t.Comments, tc)
+		}
+		threads = append(threads, t)
+	}
+	return threads, nil
+}
+
+// pageBlockOrder returns the root page and its non-page descendants in
+// document order.
+func pageBlockOrder(tree *notionclient.Tree) []string {
+	out := make([]string, 0)
+	seen := map[string]bool{}
+	var visit func(id string)
+	visit = func(id string) {
+		block := tree.Block(id)
+		if block == nil || seen[id] {
+			return
+		}
+		seen[id] = true
+		out = append(out, id)
+		if id != tree.Roots[0] && notionclient.IsPageBlock(block) {
+			return
+		}
+		for _, child := range notionclient.ContentIDs(block) {
+			visit(child)
+		}
+	}
+	visit(tree.Roots[0])
+	return out
+}
+
+// discussionAnchor finds the text of a block's title annotated with the
+// discussion (the ["m", discussionID] decoration).
+func discussionAnchor(block map[string]any, discussionID string) *commentAnchor {
+	props, _ := block["properties"].(map[string]any)
+	segments, _ := props["title"].([]any)
+	var anchor *commentAnchor
+	offset := 0
+	for _, seg := range segments {
+		parts, _ := seg.([]any)
+		if len(parts) == 0 {
+			continue
+		}
+		text, _ := parts[0].(string)
+		n := len([]rune(text))
+		if len(parts) > 1 && hasDecoration(parts[1], "m", discussionID) {
+			if anchor == nil {
+				anchor = &commentAnchor{Start: offset}
+			}
+			anchor.Text += text
+			anchor.End = offset + n
+		}
+		offset += n
+	}
+	return anchor
+}
+
+func hasDecoration(v any, tag string, value string) bool {
+	decorations, _ := v.([]any)
+	for _, d := range decorations {
+		parts, _ := d.([]any)
+		if len(parts) >= 2 {
+			t, _ := parts[0].(string)
+			s, _ := parts[1].(string)
+			if t == tag && s == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// richTextWithUsers is PlainText with user mentions rendered as @Name.
+func richTextWithUsers(v any, users map[string]notionclient.User) string {
+	segments, _ := v.([]any)
+	var b strings.Builder
+	for _, seg := range segments {
+		parts, _ := seg.([]any)
+		if len(parts) == 0 {
+			continue
+		}
+		text, _ := parts[0].(string)
+		if len(parts) > 1 {
+			if ids := notionclient.CollectUserIDs(parts[1]); len(ids) == 1 {
+				if u, ok := users[ids[0]]; ok && u.Name != "" {
+					text = "@" + u.Name
+				} else {
+					text = "@" + ids[0]
+				}
+			}
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func renderCommentsMarkdown(title string, url string, threads []commentThread) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Comments on [%s](%s)\n", title, url)
+	if len(threads) == 0 {
+		b.WriteString("\nNo comments.\n")
+		return b.String()
+	}
+	for _, t := range threads {
+		status := "open"
+		if t.Resolved {
+			status = "resolved"
+		}
+		switch {
+		case t.PageLevel:
+			fmt.Fprintf(&b, "\n## Page comment (%s)\n", status)
+		case t.Anchor != nil:
+			fmt.Fprintf(&b, "\n## On \"%s\" (%s)\n", t.Anchor.Text, status)
+		default:
+			fmt.Fprintf(&b, "\n## On %s block (%s)\n", t.BlockType, status)
+		}
+		if t.BlockText != "" {
+			fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(t.BlockText, "\n", "\n> "))
+		}
+		b.WriteString("\n")
+		for _, cm := range t.Comments {
+			author := "unknown"
+			if cm.Author != nil {
+				author = cm.Author.ID
+				if cm.Author.Name != "" {
+					author = cm.Author.Name
+				}
+			}
+			fmt.Fprintf(&b, "- **%s** (%s): %s\n", author, cm.CreatedTime, strings.ReplaceAll(cm.Text, "\n", "\n  "))
+		}
+	}
+	return b.String()
+}
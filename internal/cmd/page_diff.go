@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jodok/nocli/internal/notionclient"
+	"github.com/jodok/nocli/internal/pagediff"
+)
+
+type PageDiffCmd struct {
+	URLOrID string `arg:"" help:"Notion page URL or page ID" name:"url_or_id"`
+	From    string `name:"from" default:"latest" help:"Old state: 'latest' snapshot, snapshot version (v12), snapshot time (as for page history show --at), or a JSON file from page fetch/page history show"`
+	To      string `name:"to" default:"live" help:"New state: 'live', or anything accepted by --from"`
+	JSON    bool   `name:"json" help:"Emit changes as JSON instead of unified text"`
+	Output  string `name:"output" short:"o" help:"Write output to this file instead of stdout"`
+}
+
+func (c *PageDiffCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	pageID, err := notionclient.ParsePageID(c.URLOrID)
+	if err != nil {
+		return err
+	}
+	if client, err = client.ForRecord(ctx, "block", pageID); err != nil {
+		return err
+	}
+
+	loader := &diffStateLoader{client: client, pageID: pageID}
+	from, err := loader.load(ctx, c.From)
+	if err != nil {
+		return fmt.Errorf("--from: %w", err)
+	}
+	to, err := loader.load(ctx, c.To)
+	if err != nil {
+		return fmt.Errorf("--to: %w", err)
+	}
+
+	res := pagediff.Diff(pageID, from, to)
+	if c.JSON {
+		return writeOutput(ctx, c.Output, res)
+	}
+	if c.Output == "" {
+		return pagediff.WriteUnified(os.Stdout, res)
+	}
+	f, err := os.OpenFile(c.Output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	if err := pagediff.WriteUnified(f, res); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write output file: %w", err)
+	}
+	return f.Close()
+}
+
+type diffStateLoader struct {
+	client *notionclient.Client
+	pageID string
+	snaps  []notionclient.Snapshot
+}
+
+func (l *diffStateLoader) load(ctx context.Context, spec string) (pagediff.State, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "live" {
+		tree, err := l.client.FetchTree(ctx, []string{l.pageID}, notionclient.TreeOptions{RootOnly: true, IncludeCollections: true})
+		if err != nil {
+			return pagediff.State{}, fmt.Errorf("fetch live page: %w", err)
+		}
+		return pagediff.State{Label: "live", Records: tree.Records}, nil
+	}
+	if st, err := os.Stat(spec); err == nil && !st.IsDir() {
+		records, err := readDiffStateFile(spec)
+		if err != nil {
+			return pagediff.State{}, err
+		}
+		return pagediff.State{Label: spec, Records: records}, nil
+	}
+
+	snap, err := l.snapshot(ctx, spec)
+	if err != nil {
+		return pagediff.State{}, err
+	}
+	resp, err := l.client.GetSnapshotContents(ctx, l.pageID, snap)
+	if err != nil {
+		return pagediff.State{}, fmt.Errorf("fetch snapshot: %w", err)
+	}
+	label := fmt.Sprintf("snapshot %s (v%d)", notionclient.MillisToISO8601(snap.Timestamp), snap.Version)
+	return pagediff.State{Label: label, Records: notionclient.FlattenRecordMap(resp)}, nil
+}
+
+func (l *diffStateLoader) snapshot(ctx context.Context, spec string) (notionclient.Snapshot, error) {
+	if l.snaps == nil {
+		snaps, err := l.client.GetSnapshots(ctx, l.pageID, historySearchSize)
+		if err != nil {
+			return notionclient.Snapshot{}, fmt.Errorf("list snapshots: %w", err)
+		}
+		l.snaps = snaps
+	}
+	if len(l.snaps) == 0 {
+		return notionclient.Snapshot{}, fmt.Errorf("page %s has no snapshots", l.pageID)
+	}
+
+	if spec == "latest" {
+		return l.snaps[0], nil
+	}
+	if v, ok := strings.CutPrefix(spec, "v"); ok {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			for _, s := range l.snaps {
+				if s.Version == n {
+					return s, nil
+				}
+			}
+			return notionclient.Snapshot{}, fmt.Errorf("no snapshot with version %d", n)
+		}
+	}
+	at, err := parseHistoryTime(spec)
+	if err != nil {
+		return notionclient.Snapshot{}, fmt.Errorf("%q is not a file, 'live', 'latest', a snapshot version or a time", spec)
+	}
+	snap, ok := snapshotAt(l.snaps, at)
+	if !ok {
+		return notionclient.Snapshot{}, fmt.Errorf("no snapshot at or before %s", spec)
+	}
+	return snap, nil
+}
+
+// readDiffStateFile accepts a raw response or page history show output with
+// a recordMap, or flattened records (under "records" or at the top level).
+func readDiffStateFile(path string) (map[string]map[string]map[string]any, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("decode state file %s: %w", path, err)
+	}
+	if _, ok := doc["recordMap"].(map[string]any); ok {
+		return notionclient.FlattenRecordMap(doc), nil
+	}
+
+	raw := body
+	if records, ok := doc["records"]; ok {
+		if raw, err = json.Marshal(records); err != nil {
+			return nil, fmt.Errorf("decode state file %s: %w", path, err)
+		}
+	}
+	var flat map[string]map[string]map[string]any
+	if err := json.Unmarshal(raw, &flat); err != nil || len(flat["block"]) == 0 {
+		return nil, fmt.Errorf("%s has no recordMap or block records", path)
+	}
+	return flat, nil
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jodok/nocli/internal/notionclient"
+	"github.com/jodok/nocli/internal/pagegraph"
+	"github.com/jodok/nocli/internal/render"
+)
+
+type PageExportCmd struct {
+	URLOrID   string `arg:"" help:"Notion page URL or page ID" name:"url_or_id"`
+	Format    string `name:"format" enum:"html" default:"html" help:"Output format"`
+	Out       string `name:"out" required:"" help:"Output directory"`
+	Recursive bool   `name:"recursive" help:"Also export subpages and database rows as linked files"`
+	NoAssets  bool   `name:"no-assets" help:"Link images and files to Notion instead of downloading them"`
+	InlineCSS bool   `name:"inline-css" help:"Embed the theme in every page instead of writing style.css"`
+}
+
+const (
+	exportStylesheet = "style.css"
+	exportAssetsDir  = "assets"
+)
+
+func (c *PageExportCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	pageID, err := notionclient.ParsePageID(c.URLOrID)
+	if err != nil {
+		return err
+	}
+	root, err := fetchBlock(ctx, client, pageID)
+	if err != nil {
+		return err
+	}
+	spaceID, _ := root["space_id"].(string)
+	client = client.ForSpace(ctx, spaceID)
+
+	// Without --recursive, subpages and rows are only needed for their
+	// titles and links.
+	tree, err := client.FetchTree(ctx, []string{pageID}, notionclient.TreeOptions{RootOnly: !c.Recursive, IncludeCollections: true})
+	if err != nil {
+		return fmt.Errorf("fetch page tree: %w", err)
+	}
+
+	files := map[string]string{pageID: "index.html"}
+	order := []string{pageID}
+	if c.Recursive {
+		for _, id := range notionclient.SortedKeys(tree.Records["block"]) {
+			block := tree.Block(id)
+			if id != pageID && notionclient.IsPageBlock(block) && notionclient.IsAlive(block) {
+				files[id] = exportFileName(notionclient.PageTitle(tree.Records, block), id)
+				order = append(order, id)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(c.Out, 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+	assets := &exportAssets{client: client, dir: c.Out, spaceID: spaceID, paths: map[string]string{}}
+	users, err := notionclient.NewUserResolver(client).Resolve(ctx, notionclient.CollectUserIDs(tree.Records["block"]))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: could not resolve user names: %v\n", err)
+	}
+
+	linked, err := client.GetRecords(ctx, "block", exportLinkedPages(tree))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: could not fetch titles of linked pages: %v\n", err)
+	}
+
+	opts := render.Options{
+		PageHref: func(id string) string {
+			if name, ok := files[id]; ok {
+				return name
+			}
+			return client.PageURL(id)
+		},
+		PageTitle: func(id string) string {
+			return notionclient.BlockTitle(linked[id])
+		},
+		UserName: func(id string) string {
+			return users[id].Name
+		},
+	}
+	if !c.NoAssets {
+		opts.Asset = func(blockID string, raw string) string {
+			return assets.local(ctx, blockID, raw)
+		}
+	}
+	if !c.InlineCSS {
+		opts.Stylesheet = exportStylesheet
+		if err := os.WriteFile(filepath.Join(c.Out, exportStylesheet), []byte(render.Theme), 0o644); err != nil {
+			return fmt.Errorf("write stylesheet: %w", err)
+		}
+	}
+
+	for _, id := range order {
+		doc, err := render.Page(tree, id, opts)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(c.Out, files[id]), []byte(doc), 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", files[id], err)
+		}
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "exported %d page(s) and %d asset(s) to %s\n", len(order), assets.count, c.Out)
+	return nil
+}
+
+// exportLinkedPages returns the pages mentioned or linked from the tree that
+// were not fetched with it.
+func exportLinkedPages(tree *notionclient.Tree) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0)
+	for _, id := range notionclient.SortedKeys(tree.Records["block"]) {
+		for _, ref := range pagegraph.BlockReferences(tree.Records, tree.Block(id)) {
+			if tree.Block(ref.Target) == nil && !seen[ref.Target] {
+				seen[ref.Target] = true
+				out = append(out, ref.Target)
+			}
+		}
+	}
+	return out
+}
+
+// exportFileName returns "<slug>-<compact id>.html"; the ID keeps names
+// unique and stable across renames.
+func exportFileName(title string, id string) string {
+	slug := notionclient.Slug(title)
+	compact := strings.ReplaceAll(id, "-", "")
+	if slug == "" {
+		return compact + ".html"
+	}
+	return slug + "-" + compact + ".html"
+}
+
+// exportAssets downloads files referenced by rendered pages once each into
+// the assets directory.
+type exportAssets struct {
+	client  *notionclient.Client
+	dir     string
+	spaceID string
+	paths   map[string]string
+	count   int
+}
+
+// local returns the relative path of the downloaded file, or "" (keep the
+// remote URL) when the download fails.
+func (a *exportAssets) local(ctx context.Context, blockID string, raw string) string {
+	if p, ok := a.paths[raw]; ok {
+		return p
+	}
+	a.paths[raw] = ""
+
+	src := raw
+	if notionclient.IsNotionFileURL(raw) {
+		signed, err := a.client.GetSignedFileURLs(ctx, []notionclient.FileRef{{URL: raw, BlockID: blockID, SpaceID: a.spaceID}})
+		if err != nil || signed[0] == "" {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: could not sign %s: %v\n", raw, err)
+			return ""
+		}
+		src = signed[0]
+	}
+
+	rel := path.Join(exportAssetsDir, strings.ReplaceAll(blockID, "-", "")[:12]+"-"+assetBaseName(raw))
+	dest := filepath.Join(a.dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return ""
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return ""
+	}
+	_, err = a.client.Download(ctx, src, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(dest)
+		_, _ = fmt.Fprintf(os.Stderr, "warning: keeping remote URL for %s: %v\n", raw, err)
+		return ""
+	}
+	a.paths[raw] = rel
+	a.count++
+	return rel
+}
+
+var assetNamePattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// assetBaseName derives a safe file name from a file URL or an
+// "attachment:<id>:<name>" reference.
+func assetBaseName(raw string) string {
+	name := raw
+	if rest, ok := strings.CutPrefix(raw, "attachment:"); ok {
+		name = rest[strings.LastIndex(rest, ":")+1:]
+	} else if u, err := url.Parse(raw); err == nil {
+		name = path.Base(u.Path)
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+	}
+	name = strings.Trim(assetNamePattern.ReplaceAllString(name, "-"), "-")
+	if name == "" || name == "." {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type PageHistoryCmd struct {
+	List PageHistoryListCmd `cmd:"" default:"withargs" help:"List the page's snapshots"`
+	Show PageHistoryShowCmd `cmd:"" help:"Print the page's recordMap as it was at a point in time"`
+}
+
+type PageHistoryListCmd struct {
+	URLOrID string `arg:"" help:"Notion page URL or page ID" name:"url_or_id"`
+	Limit   int    `name:"limit" default:"100" help:"Maximum number of snapshots to list"`
+	JSON    bool   `name:"json" help:"Emit snapshots as JSON"`
+}
+
+type PageHistoryShowCmd struct {
+	URLOrID string `arg:"" help:"Notion page URL or page ID" name:"url_or_id"`
+	At      string `name:"at" required:"" help:"Point in time: RFC 3339 timestamp, YYYY-MM-DD (end of that day, UTC) or Unix milliseconds"`
+	Flatten bool   `name:"flatten" help:"Print the recordMap as table -> id -> value"`
+	Output  string `name:"output" short:"o" help:"Write JSON output to this file instead of stdout"`
+}
+
+// historySearchSize is how many snapshots are scanned to resolve --at.
+const historySearchSize = 1000
+
+func (c *PageHistoryListCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	pageID, err := notionclient.ParsePageID(c.URLOrID)
+	if err != nil {
+		return err
+	}
+	if client, err = client.ForRecord(ctx, "block", pageID); err != nil {
+		return err
+	}
+	snaps, err := client.GetSnapshots(ctx, pageID, c.Limit)
+	if err != nil {
+		return fmt.Errorf("list snapshots of %s: %w", pageID, err)
+	}
+	if c.JSON {
+		return writeOutput(ctx, "", map[string]any{"page_id": pageID, "snapshots": snaps})
+	}
+	if len(snaps) == 0 {
+		fmt.Println("no snapshots")
+		return nil
+	}
+
+	ids := make([]string, 0)
+	for _, s := range snaps {
+		ids = append(ids, s.Authors...)
+	}
+	users, err := notionclient.NewUserResolver(client).Resolve(ctx, uniqueStrings(ids))
+	if err != nil {
+		users = map[string]notionclient.User{}
+	}
+	for _, s := range snaps {
+		authors := make([]string, 0, len(s.Authors))
+		for _, id := range s.Authors {
+			if u, ok := users[id]; ok && u.Name != "" {
+				authors = append(authors, u.Name)
+			} else {
+				authors = append(authors, id)
+			}
+		}
+		fmt.Printf("%s  v%d  %s\n", notionclient.MillisToISO8601(s.Timestamp), s.Version, strings.Join(authors, ", "))
+	}
+	return nil
+}
+
+func (c *PageHistoryShowCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	pageID, err := notionclient.ParsePageID(c.URLOrID)
+	if err != nil {
+		return err
+	}
+	if client, err = client.ForRecord(ctx, "block", pageID); err != nil {
+		return err
+	}
+	at, err := parseHistoryTime(c.At)
+	if err != nil {
+		return err
+	}
+
+	snaps, err := client.GetSnapshots(ctx, pageID, historySearchSize)
+	if err != nil {
+		return fmt.Errorf("list snapshots of %s: %w", pageID, err)
+	}
+	snap, ok := snapshotAt(snaps, at)
+	if !ok {
+		return fmt.Errorf("no snapshot of %s at or before %s", pageID, at.UTC().Format(time.RFC3339))
+	}
+
+	resp, err := client.GetSnapshotContents(ctx, pageID, snap)
+	if err != nil {
+		return fmt.Errorf("fetch snapshot %s: %w", notionclient.MillisToISO8601(snap.Timestamp), err)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "snapshot %s (v%d)\n", notionclient.MillisToISO8601(snap.Timestamp), snap.Version)
+
+	out := map[string]any{"page_id": pageID, "snapshot": snap}
+	if c.Flatten {
+		out["records"] = notionclient.FlattenRecordMap(resp)
+	} else {
+		out["recordMap"] = resp["recordMap"]
+	}
+	return writeOutput(ctx, c.Output, out)
+}
+
+// snapshotAt returns the newest snapshot taken at or before at.
+func snapshotAt(snaps []notionclient.Snapshot, at time.Time) (notionclient.Snapshot, bool) {
+	ms := at.UnixMilli()
+	best, found := notionclient.Snapshot{}, false
+	for _, s := range snaps {
+		if s.Timestamp <= ms && (!found || s.Timestamp > best.Timestamp) {
+			best, found = s, true
+		}
+	}
+	return best, found
+}
+
+func parseHistoryTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Add(24*time.Hour - time.Millisecond), nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil && ms > 0 {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q: want RFC 3339, YYYY-MM-DD or Unix milliseconds", s)
+}
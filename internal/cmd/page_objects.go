@@ -2,9 +2,7 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/jodok/nocli/internal/notionclient"
@@ -36,6 +34,9 @@ func (c *PageObjectsCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if client, err = client.ForRecord(ctx, "block", pageID); err != nil {
+		return err
+	}
 
 	resp, err := client.LoadPageChunk(ctx, pageID)
 	if err != nil {
@@ -94,19 +95,5 @@ func (c *PageObjectsCmd) Run(ctx context.Context) error {
 		},
 	}
 
-	body, err := json.MarshalIndent(out, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal objects json: %w", err)
-	}
-	body = append(body, '\n')
-
-	if c.Output != "" {
-		if err := os.WriteFile(c.Output, body, 0o600); err != nil {
-			return fmt.Errorf("write output file: %w", err)
-		}
-		return nil
-	}
-
-	_, err = os.Stdout.Write(body)
-	return err
+	return writeOutput(ctx, c.Output, out)
 }
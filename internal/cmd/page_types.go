@@ -23,6 +23,9 @@ func (c *PageTypesCmd) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if client, err = client.ForRecord(ctx, "block", pageID); err != nil {
+		return err
+	}
 
 	resp, err := client.LoadPageChunk(ctx, pageID)
 	if err != nil {
@@ -54,7 +57,7 @@ func (c *PageTypesCmd) Run(ctx context.Context) error {
 		}
 	}
 
-	return writeJSON(c.Output, map[string]any{
+	return writeOutput(ctx, c.Output, map[string]any{
 		"page_id":                     pageID,
 		"seen_block_types":            seen,
 		"public_api_documented_types": notionclient.PublicAPISupportedBlockTypes,
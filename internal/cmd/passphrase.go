@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/jodok/nocli/internal/config"
+)
+
+const passphraseEnv = "NOTION_CONFIG_PASSPHRASE"
+
+// passphraseSource returns a config.PassphraseFunc that reads
+// NOTION_CONFIG_PASSPHRASE or prompts on the terminal. With confirm set, a
+// prompted passphrase must be typed twice.
+func passphraseSource(confirm bool) config.PassphraseFunc {
+	var cached string
+	return func() (string, error) {
+		if cached != "" {
+			return cached, nil
+		}
+		if env := os.Getenv(passphraseEnv); env != "" {
+			cached = env
+			return cached, nil
+		}
+
+		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if err != nil {
+			return "", fmt.Errorf("config is encrypted; set %s or run in a terminal", passphraseEnv)
+		}
+		defer tty.Close()
+
+		pass, err := readPassphrase(tty, "Config passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if confirm {
+			again, err := readPassphrase(tty, "Repeat passphrase: ")
+			if err != nil {
+				return "", err
+			}
+			if again != pass {
+				return "", fmt.Errorf("passphrases do not match")
+			}
+		}
+		if pass == "" {
+			return "", fmt.Errorf("empty passphrase")
+		}
+		cached = pass
+		return cached, nil
+	}
+}
+
+func readPassphrase(tty *os.File, prompt string) (string, error) {
+	_, _ = fmt.Fprint(tty, prompt)
+	b, err := term.ReadPassword(int(tty.Fd()))
+	_, _ = fmt.Fprintln(tty)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// progressReader reports transfer progress to stderr in 10% steps, starting
+// at 0% when the transfer begins.
+type progressReader struct {
+	r        io.Reader
+	label    string
+	total    int64
+	read     int64
+	lastStep int
+}
+
+func newProgressReader(r io.Reader, label string, total int64) *progressReader {
+	return &progressReader{r: r, label: label, total: total, lastStep: -1}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if p.total > 0 && p.lastStep < 0 {
+		p.report(0)
+	}
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.total > 0 {
+		if pct := int(p.read * 100 / p.total); pct/10 > p.lastStep {
+			p.report(pct)
+		}
+	}
+	return n, err
+}
+
+func (p *progressReader) report(pct int) {
+	p.lastStep = pct / 10
+	_, _ = fmt.Fprintf(os.Stderr, "%s: %3d%% (%s/%s)\n", p.label, pct, humanBytes(p.read), humanBytes(p.total))
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
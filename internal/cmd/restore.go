@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jodok/nocli/internal/archive"
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type RestoreCmd struct {
+	Archive string `arg:"" name:"archive" help:"Backup archive directory written by 'nocli backup'" type:"existingdir"`
+	Page    string `name:"page" required:"" help:"Page ID inside the archive to restore (with its subtree)"`
+	To      string `name:"to" required:"" help:"Parent page/block the restored page is appended to"`
+	DryRun  bool   `name:"dry-run" help:"Print the restore plan without writing anything"`
+	Batch   int    `name:"batch" default:"100" help:"Operations per transaction"`
+}
+
+type restoreItem struct {
+	Table    string `json:"table"`
+	OldID    string `json:"old_id"`
+	NewID    string `json:"new_id"`
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	ParentID string `json:"parent_id"`
+}
+
+type restorePlan struct {
+	Archive      string         `json:"archive"`
+	PageID       string         `json:"page_id"`
+	NewPageID    string         `json:"new_page_id"`
+	ParentID     string         `json:"parent_id"`
+	SpaceID      string         `json:"space_id"`
+	DryRun       bool           `json:"dry_run"`
+	Counts       map[string]int `json:"counts"`
+	IDsRewritten int            `json:"ids_rewritten"`
+	Items        []restoreItem  `json:"items"`
+
+	records []restoreRecord
+}
+
+type restoreRecord struct {
+	table string
+	id    string
+	value map[string]any
+}
+
+func (c *RestoreCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	pageID, err := notionclient.ParsePageID(c.Page)
+	if err != nil {
+		return fmt.Errorf("parse page id: %w", err)
+	}
+	parentID, err := notionclient.ParsePageID(c.To)
+	if err != nil {
+		return fmt.Errorf("parse target parent id: %w", err)
+	}
+
+	arc, err := archive.Open(c.Archive)
+	if err != nil {
+		return err
+	}
+	if !arc.Manifest().Complete {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: archive %s is incomplete; restoring what it contains\n", c.Archive)
+	}
+
+	parent, err := fetchBlock(ctx, client, parentID)
+	if err != nil {
+		return fmt.Errorf("fetch target parent: %w", err)
+	}
+	spaceID, _ := parent["space_id"].(string)
+	if spaceID == "" {
+		return fmt.Errorf("target parent %s has no space_id", parentID)
+	}
+	client = client.ForSpace(ctx, spaceID)
+
+	plan, err := planRestore(arc, pageID, parentID, spaceID, client.CurrentUserID())
+	if err != nil {
+		return err
+	}
+	plan.Archive = c.Archive
+	plan.DryRun = c.DryRun
+	if c.DryRun {
+		return writeOutput(ctx, "", plan)
+	}
+
+	ops := make([]notionclient.Operation, 0, len(plan.records)+1)
+	for _, r := range plan.records {
+		ops = append(ops, notionclient.SetOp(r.table, r.id, spaceID, nil, r.value))
+	}
+	ops = append(ops, notionclient.ListAfterOp("block", parentID, spaceID, []string{"content"}, plan.NewPageID, ""))
+
+	// Records are ordered parents-first, so every batch only references
+	// records created by earlier batches or already live.
+	batch := max(c.Batch, 1)
+	for start := 0; start < len(ops); start += batch {
+		end := min(start+batch, len(ops))
+		if _, err := client.SaveTransactions(ctx, spaceID, "nocli.restore", ops[start:end]); err != nil {
+			return fmt.Errorf("restore operations %d-%d of %d: %w", start+1, end, len(ops), err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "restore: %d/%d operations applied\n", end, len(ops))
+	}
+
+	return writeOutput(ctx, "", plan)
+}
+
+// planRestore collects the subtree of pageID from the archive, mints new IDs
+// and rewrites every reference between restored records.
+func planRestore(arc *archive.Archive, pageID string, parentID string, spaceID string, userID string) (*restorePlan, error) {
+	blocks, err := arc.Records("block")
+	if err != nil {
+		return nil, err
+	}
+	collections, err := arc.Records("collection")
+	if err != nil {
+		return nil, err
+	}
+	views, err := arc.Records("collection_view")
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks[pageID]) == 0 {
+		return nil, fmt.Errorf("page %s is not in archive %s", pageID, arc.Dir())
+	}
+
+	rowsByCollection := map[string][]string{}
+	for _, id := range notionclient.SortedKeys(blocks) {
+		b := blocks[id]
+		if table, _ := b["parent_table"].(string); table == "collection" {
+			pid, _ := b["parent_id"].(string)
+			rowsByCollection[pid] = append(rowsByCollection[pid], id)
+		}
+	}
+
+	// Breadth-first from the page so parents always precede children.
+	ordered := make([]restoreRecord, 0)
+	seen := map[string]bool{}
+	queue := []string{pageID}
+	add := func(table string, id string, value map[string]any) {
+		if key := table + "/" + id; !seen[key] && len(value) > 0 {
+			seen[key] = true
+			ordered = append(ordered, restoreRecord{table: table, id: id, value: value})
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		b := blocks[id]
+		if len(b) == 0 || seen["block/"+id] {
+			continue
+		}
+		add("block", id, b)
+		queue = append(queue, notionclient.ContentIDs(b)...)
+
+		for _, viewID := range notionclient.StringList(b["view_ids"]) {
+			add("collection_view", viewID, views[viewID])
+		}
+		if collectionID := notionclient.CollectionIDForBlock(b, views); collectionID != "" {
+			// Linked databases point at collections owned elsewhere; only
+			// restore collections this block actually owns.
+			if col := collections[collectionID]; len(col) > 0 && col["parent_id"] == id {
+				add("collection", collectionID, col)
+				queue = append(queue, rowsByCollection[collectionID]...)
+			}
+		}
+	}
+
+	idMap := make(map[string]string, len(ordered))
+	for _, r := range ordered {
+		newID, err := notionclient.NewID()
+		if err != nil {
+			return nil, err
+		}
+		idMap[r.id] = newID
+	}
+	remapper := newIDRemapper(idMap)
+
+	now := time.Now().UnixMilli()
+	plan := &restorePlan{
+		PageID:    pageID,
+		NewPageID: idMap[pageID],
+		ParentID:  parentID,
+		SpaceID:   spaceID,
+		Counts:    map[string]int{},
+		Items:     make([]restoreItem, 0, len(ordered)),
+	}
+	for _, r := range ordered {
+		value := remapper.value(r.value).(map[string]any)
+		value["id"] = idMap[r.id]
+		value["space_id"] = spaceID
+		value["version"] = 1
+		value["alive"] = true
+		value["last_edited_time"] = now
+		if userID != "" {
+			value["last_edited_by_id"] = userID
+			value["last_edited_by_table"] = "notion_user"
+		}
+		// Comments and page-level sharing are not part of the archive.
+		delete(value, "discussions")
+		if r.table == "block" && r.id == pageID {
+			value["parent_id"] = parentID
+			value["parent_table"] = "block"
+			delete(value, "permissions")
+		}
+
+		newParent, _ := value["parent_id"].(string)
+		typ, _ := value["type"].(string)
+		title := notionclient.BlockTitle(value)
+		if r.table == "collection" {
+			title = notionclient.PlainText(value["name"])
+		} else if r.table == "collection_view" {
+			title, _ = value["name"].(string)
+		}
+		plan.Items = append(plan.Items, restoreItem{
+			Table:    r.table,
+			OldID:    r.id,
+			NewID:    idMap[r.id],
+			Type:     typ,
+			Title:    title,
+			ParentID: newParent,
+		})
+		plan.Counts[r.table]++
+		plan.records = append(plan.records, restoreRecord{table: r.table, id: idMap[r.id], value: value})
+	}
+	plan.IDsRewritten = remapper.count
+	return plan, nil
+}
+
+// idRemapper deep-copies record values, replacing restored record IDs in both
+// dashed and compact form (compact IDs appear in page links).
+type idRemapper struct {
+	dashed  map[string]string
+	compact *strings.Replacer
+	count   int
+}
+
+func newIDRemapper(idMap map[string]string) *idRemapper {
+	pairs := make([]string, 0, len(idMap)*4)
+	for oldID, newID := range idMap {
+		pairs = append(pairs, oldID, newID)
+		pairs = append(pairs, strings.ReplaceAll(oldID, "-", ""), strings.ReplaceAll(newID, "-", ""))
+	}
+	return &idRemapper{dashed: idMap, compact: strings.NewReplacer(pairs...)}
+}
+
+func (m *idRemapper) value(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, val := range x {
+			out[m.string(k)] = m.value(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, val := range x {
+			out[i] = m.value(val)
+		}
+		return out
+	case string:
+		return m.string(x)
+	default:
+		return v
+	}
+}
+
+func (m *idRemapper) string(s string) string {
+	if newID, ok := m.dashed[s]; ok {
+		m.count++
+		return newID
+	}
+	if len(s) < 32 {
+		return s
+	}
+	out := m.compact.Replace(s)
+	if out != s {
+		m.count++
+	}
+	return out
+}
@@ -14,11 +14,14 @@ import (
 
 type RootFlags struct {
 	ConfigPath   string `name:"config" default:"~/.nocli.json" help:"Path to config file" env:"NOTION_CONFIG"`
+	Profile      string `name:"profile" help:"Named auth profile from the config file (default: default_profile)" env:"NOTION_PROFILE"`
 	BaseURL      string `name:"base-url" default:"https://www.notion.so" help:"Notion base URL" env:"NOTION_BASE_URL"`
 	TokenV2      string `name:"token-v2" help:"Notion token_v2 cookie value" env:"NOTION_TOKEN_V2"`
 	NotionUserID string `name:"notion-user-id" help:"notion_user_id cookie value" env:"NOTION_USER_ID"`
 	ActiveUserID string `name:"active-user-id" help:"x-notion-active-user-header value" env:"NOTION_ACTIVE_USER_ID"`
+	AsUser       string `name:"as-user" help:"Act as this logged-in user (email or user ID) for every request" env:"NOTION_AS_USER"`
 	Cookie       string `name:"cookie" help:"Raw Cookie header (overrides token_v2/notion_user_id)" env:"NOTION_COOKIE"`
+	ResolveUsers bool   `name:"resolve-users" help:"Add names and emails for user IDs in JSON output" env:"NOTION_RESOLVE_USERS"`
 }
 
 type CLI struct {
@@ -26,6 +29,17 @@ type CLI struct {
 	Page       PageCmd       `cmd:"" help:"Page operations"`
 	Block      BlockCmd      `cmd:"" help:"Block operations"`
 	Collection CollectionCmd `cmd:"" help:"Collection operations"`
+	Comment    CommentCmd    `cmd:"" help:"Add and resolve comments"`
+	Space      SpaceCmd      `cmd:"" help:"Workspace discovery"`
+	Search     SearchCmd     `cmd:"" help:"Search pages and databases in a workspace"`
+	Graph      GraphCmd      `cmd:"" help:"Export the link graph of a page tree as DOT, GraphML or JSON"`
+	Lint       LintCmd       `cmd:"" help:"Check page trees for problems"`
+	Watch      WatchCmd      `cmd:"" help:"Stream change events of a page or database as NDJSON"`
+	Site       SiteCmd       `cmd:"" help:"Build static site content from a page tree"`
+	Export     ExportCmd     `cmd:"" help:"Export operations"`
+	Backup     BackupCmd     `cmd:"" help:"Back up a whole space into an on-disk record archive"`
+	Restore    RestoreCmd    `cmd:"" help:"Recreate a page subtree from a backup archive"`
+	API        APICmd        `cmd:"" name:"api" help:"POST raw JSON to any /api/v3/ endpoint"`
 	Auth       AuthCmd       `cmd:"" help:"Authentication helpers"`
 	Objects    ObjectsCmd    `cmd:"" help:"Object discovery shortcuts"`
 }
@@ -61,11 +75,26 @@ func Execute(args []string) error {
 		return err
 	}
 
-	baseURL := firstNonEmpty(cli.BaseURL, cfg.BaseURL)
-	tokenV2 := firstNonEmpty(strings.TrimSpace(cli.TokenV2), cfg.TokenV2)
-	notionUserID := firstNonEmpty(strings.TrimSpace(cli.NotionUserID), cfg.NotionUserID)
-	activeUserID := firstNonEmpty(strings.TrimSpace(cli.ActiveUserID), cfg.ActiveUserID)
-	cookie := firstNonEmpty(strings.TrimSpace(cli.Cookie), cfg.Cookie)
+	profileName := cfg.ProfileName(cli.Profile)
+	profile, found := cfg.Lookup(profileName)
+	if !found && !managesConfig(ctx.Command()) {
+		err := fmt.Errorf("unknown profile %q in %s; see 'nocli auth profiles list'", profileName, config.ResolvePath(cli.ConfigPath))
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return err
+	}
+
+	if profile.Locked() && !managesConfig(ctx.Command()) && strings.TrimSpace(cli.TokenV2) == "" && strings.TrimSpace(cli.Cookie) == "" {
+		if profile, err = config.Unlock(profileName, profile, passphraseSource(false)); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			return err
+		}
+	}
+
+	baseURL := firstNonEmpty(cli.BaseURL, profile.BaseURL)
+	tokenV2 := firstNonEmpty(strings.TrimSpace(cli.TokenV2), profile.TokenV2)
+	notionUserID := firstNonEmpty(strings.TrimSpace(cli.NotionUserID), profile.NotionUserID)
+	activeUserID := firstNonEmpty(strings.TrimSpace(cli.ActiveUserID), profile.ActiveUserID)
+	cookie := firstNonEmpty(strings.TrimSpace(cli.Cookie), profile.Cookie)
 
 	client, err := notionclient.New(notionclient.Options{
 		BaseURL:      strings.TrimSpace(baseURL),
@@ -73,13 +102,25 @@ func Execute(args []string) error {
 		NotionUserID: strings.TrimSpace(notionUserID),
 		ActiveUserID: strings.TrimSpace(activeUserID),
 		Cookie:       strings.TrimSpace(cookie),
+		// An explicit active user wins over the per-space user mapping.
+		PinActiveUser: strings.TrimSpace(cli.ActiveUserID) != "",
 	})
 	if err != nil {
 		return err
 	}
+	if asUser := strings.TrimSpace(cli.AsUser); asUser != "" && !managesConfig(ctx.Command()) {
+		if client, err = selectUser(client, asUser); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			return err
+		}
+	}
 
 	runCtx := context.WithValue(context.Background(), clientContextKey{}, client)
 	runCtx = context.WithValue(runCtx, configPathContextKey{}, config.ResolvePath(cli.ConfigPath))
+	runCtx = context.WithValue(runCtx, profileContextKey{}, profileName)
+	if cli.ResolveUsers {
+		runCtx = context.WithValue(runCtx, userResolverContextKey{}, notionclient.NewUserResolver(client))
+	}
 	ctx.BindTo(runCtx, (*context.Context)(nil))
 
 	if err := ctx.Run(); err != nil {
@@ -91,8 +132,28 @@ func Execute(args []string) error {
 	return nil
 }
 
+// selectUser pins the client to one of the users logged in with the cookie.
+func selectUser(client *notionclient.Client, emailOrID string) (*notionclient.Client, error) {
+	userID, err := client.ResolveUser(context.Background(), emailOrID)
+	if err != nil {
+		return nil, fmt.Errorf("--as-user: %w", err)
+	}
+	loggedIn := client.LoggedInUserIDs()
+	for _, id := range loggedIn {
+		if id == userID {
+			return client.WithActiveUser(userID), nil
+		}
+	}
+	if len(loggedIn) == 0 {
+		return client.WithActiveUser(userID), nil
+	}
+	return nil, fmt.Errorf("--as-user: %s is not logged in with this cookie (logged in: %s)", emailOrID, strings.Join(loggedIn, ", "))
+}
+
 type clientContextKey struct{}
 type configPathContextKey struct{}
+type profileContextKey struct{}
+type userResolverContextKey struct{}
 
 func ClientFromContext(ctx context.Context) *notionclient.Client {
 	v := ctx.Value(clientContextKey{})
@@ -121,6 +182,26 @@ func ConfigPathFromContext(ctx context.Context) string {
 	return config.ResolvePath("")
 }
 
+// ProfileFromContext returns the resolved auth profile name for this run.
+func ProfileFromContext(ctx context.Context) string {
+	if s, ok := ctx.Value(profileContextKey{}).(string); ok && s != "" {
+		return s
+	}
+	return config.DefaultProfileName
+}
+
+// managesConfig reports whether a command reads and writes profiles itself:
+// it may target a profile that does not exist yet and unlocks secrets only
+// when it needs them.
+func managesConfig(command string) bool {
+	for _, prefix := range []string{"auth import", "auth profiles", "auth encrypt", "auth decrypt"} {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func hasAnyAuthMaterial() bool {
 	envToken := strings.TrimSpace(os.Getenv("NOTION_TOKEN_V2"))
 	envUser := strings.TrimSpace(os.Getenv("NOTION_USER_ID"))
@@ -134,10 +215,14 @@ func hasAnyAuthMaterial() bool {
 	if err != nil {
 		return false
 	}
-	if strings.TrimSpace(cfg.Cookie) != "" {
+	profile, ok := cfg.Lookup(cfg.ProfileName(os.Getenv("NOTION_PROFILE")))
+	if !ok {
+		return false
+	}
+	if strings.TrimSpace(profile.Cookie) != "" || profile.Locked() {
 		return true
 	}
-	return strings.TrimSpace(cfg.TokenV2) != "" && strings.TrimSpace(cfg.NotionUserID) != ""
+	return strings.TrimSpace(profile.TokenV2) != "" && strings.TrimSpace(profile.NotionUserID) != ""
 }
 
 func printAuthBootstrapHint() {
@@ -146,8 +231,8 @@ func printAuthBootstrapHint() {
 	_, _ = fmt.Fprintln(os.Stdout, "Quick setup:")
 	_, _ = fmt.Fprintln(os.Stdout, "  1) Open Notion in browser and sign in.")
 	_, _ = fmt.Fprintln(os.Stdout, "  2) DevTools -> Network -> pick a /api/v3/... request.")
-	_, _ = fmt.Fprintln(os.Stdout, "  3) Right click -> Copy -> Copy as cURL.")
-	_, _ = fmt.Fprintln(os.Stdout, "  4) Run: pbpaste | nocli auth import-curl")
+	_, _ = fmt.Fprintln(os.Stdout, "  3) Right click -> Copy -> Copy as cURL (or fetch/PowerShell, or save a HAR).")
+	_, _ = fmt.Fprintln(os.Stdout, "  4) Run: pbpaste | nocli auth import")
 }
 
 func printTopLevelHelp() {
@@ -160,7 +245,7 @@ func printTopLevelHelp() {
 	_, _ = fmt.Fprintln(os.Stdout, "  nocli page objects <url-or-id>")
 	_, _ = fmt.Fprintln(os.Stdout, "  nocli block get <block-id>")
 	_, _ = fmt.Fprintln(os.Stdout, "  nocli collection query <collection-id> <view-id>")
-	_, _ = fmt.Fprintln(os.Stdout, "  nocli auth import-curl")
+	_, _ = fmt.Fprintln(os.Stdout, "  nocli auth import")
 	_, _ = fmt.Fprintln(os.Stdout, "")
 	_, _ = fmt.Fprintln(os.Stdout, "Run 'nocli --help' for full help.")
 }
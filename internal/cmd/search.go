@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type SearchCmd struct {
+	Query       string   `arg:"" name:"query" help:"Search text"`
+	Space       string   `name:"space" help:"Space ID to search (default: every space of the logged-in users)"`
+	Type        string   `name:"type" enum:"any,page,database" default:"any" help:"Only return pages or databases"`
+	CreatedBy   []string `name:"created-by" help:"Only pages created by these users (email, user ID or 'me'); repeatable"`
+	EditedAfter string   `name:"edited-after" help:"Only pages edited on or after this date (YYYY-MM-DD)"`
+	Limit       int      `name:"limit" default:"20" help:"Maximum number of results"`
+	JSON        bool     `name:"json" help:"Emit results as JSON"`
+}
+
+type searchHit struct {
+	ID             string   `json:"id"`
+	Type           string   `json:"type"`
+	Title          string   `json:"title"`
+	Breadcrumb     []string `json:"breadcrumb"`
+	URL            string   `json:"url"`
+	LastEditedTime string   `json:"last_edited_time,omitempty"`
+	SpaceID        string   `json:"space_id"`
+}
+
+// maxSearchLimit caps the page size when paging through search results.
+const maxSearchLimit = 1000
+
+func (c *SearchCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+	if c.Limit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	var editedAfter time.Time
+	if c.EditedAfter != "" {
+		t, err := time.Parse("2006-01-02", c.EditedAfter)
+		if err != nil {
+			return fmt.Errorf("invalid --edited-after (want YYYY-MM-DD): %w", err)
+		}
+		editedAfter = t
+	}
+	// "me" depends on the user that can see each space, so it is resolved in
+	// searchSpace after ForSpace.
+	createdBy := make([]string, 0, len(c.CreatedBy))
+	createdByMe := false
+	for _, u := range c.CreatedBy {
+		if strings.EqualFold(strings.TrimSpace(u), "me") {
+			createdByMe = true
+			continue
+		}
+		id, err := resolveCreator(ctx, client, u)
+		if err != nil {
+			return fmt.Errorf("--created-by: %w", err)
+		}
+		createdBy = append(createdBy, id)
+	}
+
+	spaceIDs, err := c.spaceIDs(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	hits := make([]searchHit, 0)
+	for _, spaceID := range spaceIDs {
+		opts := notionclient.SearchOptions{
+			Query:       c.Query,
+			SpaceID:     spaceID,
+			CreatedBy:   createdBy,
+			EditedAfter: c.EditedAfter,
+		}
+		found, err := c.searchSpace(ctx, client, opts, createdByMe, editedAfter, c.Limit-len(hits))
+		if err != nil {
+			return err
+		}
+		hits = append(hits, found...)
+		if len(hits) >= c.Limit {
+			break
+		}
+	}
+
+	if c.JSON {
+		return writeOutput(ctx, "", map[string]any{"query": c.Query, "results": hits})
+	}
+	if len(hits) == 0 {
+		fmt.Println("no results")
+		return nil
+	}
+	for _, h := range hits {
+		fmt.Println(h.Title)
+		if len(h.Breadcrumb) > 0 {
+			fmt.Printf("  %s\n", strings.Join(h.Breadcrumb, " / "))
+		}
+		fmt.Printf("  %s", h.URL)
+		if h.LastEditedTime != "" {
+			fmt.Printf("  (edited %s)", h.LastEditedTime)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// resolveCreator maps an email or user ID to a user ID. Emails of logged-in
+// users resolve locally; any other email is looked up with findUser.
+func resolveCreator(ctx context.Context, client *notionclient.Client, emailOrID string) (string, error) {
+	id, err := client.ResolveUser(ctx, emailOrID)
+	if err == nil || !strings.Contains(emailOrID, "@") {
+		return id, err
+	}
+	id, findErr := client.FindUser(ctx, strings.TrimSpace(emailOrID))
+	if findErr != nil {
+		return "", fmt.Errorf("%w; find user: %w", err, findErr)
+	}
+	return id, nil
+}
+
+func (c *SearchCmd) spaceIDs(ctx context.Context, client *notionclient.Client) ([]string, error) {
+	if strings.TrimSpace(c.Space) != "" {
+		id, err := notionclient.ParsePageID(c.Space)
+		if err != nil {
+			return nil, fmt.Errorf("parse space id: %w", err)
+		}
+		return []string{id}, nil
+	}
+	resp, err := client.GetSpaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load spaces: %w", err)
+	}
+	ids := notionclient.SortedKeys(notionclient.FlattenSpaces(resp)["space"])
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no spaces visible to the logged-in users; pass --space")
+	}
+	return ids, nil
+}
+
+// searchSpace pages through results by re-running the search with a growing
+// limit, since the endpoint has no cursor, and keeps up to want matching hits.
+// With createdByMe, the space's active user is added to opts.CreatedBy.
+func (c *SearchCmd) searchSpace(ctx context.Context, client *notionclient.Client, opts notionclient.SearchOptions, createdByMe bool, editedAfter time.Time, want int) ([]searchHit, error) {
+	client = client.ForSpace(ctx, opts.SpaceID)
+	if createdByMe {
+		opts.CreatedBy = append(append([]string{}, opts.CreatedBy...), client.CurrentUserID())
+	}
+	crumbs := newBreadcrumbs(client)
+	seen := map[string]bool{}
+	hits := make([]searchHit, 0)
+
+	opts.Limit = max(want, 20)
+	for {
+		resp, err := client.Search(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("search space %s: %w", opts.SpaceID, err)
+		}
+		crumbs.add(notionclient.FlattenRecordMap(resp))
+
+		ids := notionclient.SearchResultIDs(resp)
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			block, err := crumbs.record(ctx, "block", id)
+			if err != nil {
+				return nil, err
+			}
+			if block == nil || !c.matches(block, editedAfter) {
+				continue
+			}
+			path, err := crumbs.path(ctx, block)
+			if err != nil {
+				return nil, err
+			}
+			typ, _ := block["type"].(string)
+			hits = append(hits, searchHit{
+				ID:             id,
+				Type:           typ,
+				Title:          titleOr(notionclient.BlockTitle(block)),
+				Breadcrumb:     path,
+				URL:            client.PageURL(id),
+				LastEditedTime: notionclient.MillisToISO8601(block["last_edited_time"]),
+				SpaceID:        opts.SpaceID,
+			})
+			if len(hits) >= want {
+				return hits, nil
+			}
+		}
+
+		if len(ids) < opts.Limit || opts.Limit >= maxSearchLimit {
+			return hits, nil
+		}
+		opts.Limit = min(opts.Limit*2, maxSearchLimit)
+	}
+}
+
+func (c *SearchCmd) matches(block map[string]any, editedAfter time.Time) bool {
+	typ, _ := block["type"].(string)
+	switch c.Type {
+	case "page":
+		if typ != "page" {
+			return false
+		}
+	case "database":
+		if typ != "collection_view_page" && typ != "collection_view" {
+			return false
+		}
+	}
+	if !editedAfter.IsZero() {
+		if t, err := time.Parse(time.RFC3339Nano, notionclient.MillisToISO8601(block["last_edited_time"])); err == nil && t.Before(editedAfter) {
+			return false
+		}
+	}
+	return true
+}
+
+func titleOr(title string) string {
+	if strings.TrimSpace(title) == "" {
+		return "Untitled"
+	}
+	return title
+}
+
+// breadcrumbs resolves the ancestor titles of blocks, fetching parents that
+// are not already known.
+type breadcrumbs struct {
+	client  *notionclient.Client
+	records map[string]map[string]map[string]any
+}
+
+func newBreadcrumbs(client *notionclient.Client) *breadcrumbs {
+	return &breadcrumbs{client: client, records: map[string]map[string]map[string]any{}}
+}
+
+func (b *breadcrumbs) add(flat map[string]map[string]map[string]any) {
+	for table, rows := range flat {
+		if b.records[table] == nil {
+			b.records[table] = map[string]map[string]any{}
+		}
+		for id, row := range rows {
+			b.records[table][id] = row
+		}
+	}
+}
+
+func (b *breadcrumbs) record(ctx context.Context, table string, id string) (map[string]any, error) {
+	if row, ok := b.records[table][id]; ok {
+		return row, nil
+	}
+	rows, err := b.client.GetRecords(ctx, table, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s %s: %w", table, id, err)
+	}
+	b.add(map[string]map[string]map[string]any{table: {id: rows[id]}})
+	return rows[id], nil
+}
+
+// path returns the titles from the top-level ancestor down to the direct
+// parent of block.
+func (b *breadcrumbs) path(ctx context.Context, block map[string]any) ([]string, error) {
+	path := make([]string, 0)
+	cur := block
+	for depth := 0; depth < 32; depth++ {
+		parentID, _ := cur["parent_id"].(string)
+		parentTable, _ := cur["parent_table"].(string)
+		if parentID == "" || parentTable == "space" || parentTable == "" {
+			break
+		}
+		parent, err := b.record(ctx, parentTable, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			break
+		}
+		switch parentTable {
+		case "block":
+			// Inline databases are reached through their collection; skip the
+			// view block so the database name is listed once.
+			if typ, _ := parent["type"].(string); typ != "collection_view" {
+				path = append(path, titleOr(notionclient.BlockTitle(parent)))
+			}
+		case "collection":
+			path = append(path, titleOr(notionclient.PlainText(parent["name"])))
+		case "team":
+			name, _ := parent["name"].(string)
+			path = append(path, titleOr(name))
+		}
+		cur = parent
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jodok/nocli/internal/archive"
+	"github.com/jodok/nocli/internal/notionclient"
+	"github.com/jodok/nocli/internal/render"
+	"github.com/jodok/nocli/internal/site"
+)
+
+type SiteCmd struct {
+	Build SiteBuildCmd `cmd:"" help:"Export a page tree as Markdown content for a static site generator"`
+}
+
+type SiteBuildCmd struct {
+	Root         string   `arg:"" help:"Root page URL or page ID" name:"root"`
+	Generator    string   `name:"generator" enum:"hugo,jekyll" default:"hugo" help:"Site generator the content is laid out for"`
+	Out          string   `name:"out" required:"" help:"Content directory (Hugo's content/ or the Jekyll source directory)"`
+	StaticDir    string   `name:"static-dir" help:"Directory for downloaded files (default: static/ next to --out for Hugo, --out for Jekyll)"`
+	TagsProperty []string `name:"tags-property" help:"Database properties to take tags from (default: every multi-select property); repeatable"`
+	RowLimit     int      `name:"row-limit" default:"1000" help:"Maximum rows fetched per database"`
+	NoAssets     bool     `name:"no-assets" help:"Link images and files to Notion instead of downloading them"`
+	Force        bool     `name:"force" help:"Rebuild every page, ignoring the previous build"`
+}
+
+func (c *SiteBuildCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	rootID, err := notionclient.ParsePageID(c.Root)
+	if err != nil {
+		return err
+	}
+	root, err := fetchBlock(ctx, client, rootID)
+	if err != nil {
+		return err
+	}
+	spaceID, _ := root["space_id"].(string)
+	client = client.ForSpace(ctx, spaceID)
+
+	prev, built, err := site.LoadManifest(c.Out)
+	if err != nil {
+		return err
+	}
+	if built && !c.Force && (prev.RootID != rootID || prev.Generator != c.Generator) {
+		return fmt.Errorf("%s was built from %s for %s; use --force to replace it", c.Out, prev.RootID, prev.Generator)
+	}
+
+	var cached map[string]map[string]map[string]any
+	if built && !c.Force {
+		if cached, err = refreshSiteRecords(ctx, client, prev.Records); err != nil {
+			return err
+		}
+	}
+	tree, err := client.FetchTree(ctx, []string{rootID}, notionclient.TreeOptions{
+		IncludeCollections: true,
+		RowLimit:           c.RowLimit,
+		Cached: func(table string, id string) (map[string]any, bool) {
+			v, ok := cached[table][id]
+			return v, ok
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("fetch page tree: %w", err)
+	}
+	pages := site.Plan(tree, rootID, c.Generator)
+	if len(pages) == 0 {
+		return fmt.Errorf("page %s is not in the fetched tree", rootID)
+	}
+	urls := map[string]string{}
+	paths := map[string]bool{}
+	for _, p := range pages {
+		urls[p.ID] = p.URL
+		paths[p.Path] = true
+	}
+
+	if err := os.MkdirAll(c.Out, 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+	removed := 0
+	for _, id := range notionclient.SortedKeys(prev.Pages) {
+		if old := prev.Pages[id].Path; !paths[old] {
+			if removeSiteFile(c.Out, old) {
+				removed++
+			}
+		}
+	}
+
+	staticDir := c.StaticDir
+	if staticDir == "" {
+		staticDir = c.Out
+		if c.Generator == site.Hugo {
+			staticDir = filepath.Join(filepath.Dir(filepath.Clean(c.Out)), "static")
+		}
+	}
+	assets := &exportAssets{client: client, dir: staticDir, spaceID: spaceID, paths: map[string]string{}}
+	users, err := notionclient.NewUserResolver(client).Resolve(ctx, notionclient.CollectUserIDs(tree.Records["block"]))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: could not resolve user names: %v\n", err)
+	}
+	linked := map[string]map[string]any{}
+	missing := make([]string, 0)
+	for _, id := range exportLinkedPages(tree) {
+		if v, ok := cached["block"][id]; ok {
+			linked[id] = v
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	fetched, err := client.GetRecords(ctx, "block", missing)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: could not fetch titles of linked pages: %v\n", err)
+	}
+	for id, v := range fetched {
+		linked[id] = v
+	}
+
+	next := site.Manifest{Generator: c.Generator, RootID: rootID, Pages: map[string]site.ManifestPage{}, Records: siteRecords(tree, linked)}
+	settings := []string{c.Generator, strings.Join(c.TagsProperty, ","), staticDir, fmt.Sprint(c.NoAssets)}
+	userName := func(id string) string {
+		return users[id].Name
+	}
+	written, skipped := 0, 0
+	for _, p := range pages {
+		fingerprint := site.Fingerprint(tree, p, urls, linked, userName, settings...)
+		dest := filepath.Join(c.Out, filepath.FromSlash(p.Path))
+		if old, ok := prev.Pages[p.ID]; ok && !c.Force && old.Path == p.Path && old.Fingerprint == fingerprint {
+			if _, err := os.Stat(dest); err == nil {
+				next.Pages[p.ID] = old
+				skipped++
+				continue
+			}
+		}
+
+		from := p.URL
+		opts := render.Options{
+			PageHref: func(id string) string {
+				if u, ok := urls[id]; ok {
+					return site.RelativeURL(from, u)
+				}
+				return client.PageURL(id)
+			},
+			PageTitle: func(id string) string {
+				return notionclient.BlockTitle(linked[id])
+			},
+			UserName: userName,
+		}
+		if !c.NoAssets {
+			opts.Asset = func(blockID string, raw string) string {
+				if rel := assets.local(ctx, blockID, raw); rel != "" {
+					return site.RelativeURL(from, "/"+rel)
+				}
+				return ""
+			}
+		}
+		body, err := render.Markdown(tree, p.ID, opts)
+		if err != nil {
+			return err
+		}
+		doc := c.frontMatter(client, tree, p, users).Render(c.Generator)
+		if body != "" {
+			doc += "\n" + site.EscapeTemplates(body, c.Generator)
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return fmt.Errorf("create %s: %w", filepath.Dir(dest), err)
+		}
+		if err := os.WriteFile(dest, []byte(doc), 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", p.Path, err)
+		}
+		next.Pages[p.ID] = site.ManifestPage{Path: p.Path, Fingerprint: fingerprint}
+		written++
+	}
+	if err := site.SaveManifest(c.Out, next); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "built %d page(s), %d unchanged, %d removed, %d asset(s) downloaded into %s\n", written, skipped, removed, assets.count, c.Out)
+	return nil
+}
+
+// refreshSiteRecords brings the records of the previous build up to date,
+// fetching only those whose version changed. Records that are no longer
+// readable are dropped so the tree fetch reports them.
+func refreshSiteRecords(ctx context.Context, client *notionclient.Client, records map[string]map[string]map[string]any) (map[string]map[string]map[string]any, error) {
+	out := map[string]map[string]map[string]any{}
+	for _, table := range notionclient.SortedKeys(records) {
+		versions := map[string]int64{}
+		for id, v := range records[table] {
+			versions[id] = archive.RecordVersion(v)
+		}
+		changed, err := client.ChangedRecords(ctx, table, versions)
+		if err != nil {
+			return nil, fmt.Errorf("check %s records for changes: %w", table, err)
+		}
+		out[table] = map[string]map[string]any{}
+		for id, v := range records[table] {
+			if nv, ok := changed[id]; ok {
+				if nv["id"] == nil {
+					continue
+				}
+				v = nv
+			}
+			out[table][id] = v
+		}
+	}
+	return out, nil
+}
+
+// siteRecords returns the records a build used, for the next build to reuse.
+func siteRecords(tree *notionclient.Tree, linked map[string]map[string]any) map[string]map[string]map[string]any {
+	out := map[string]map[string]map[string]any{}
+	for _, table := range []string{"block", "collection", "collection_view"} {
+		out[table] = map[string]map[string]any{}
+		for id, v := range tree.Records[table] {
+			if v["id"] != nil {
+				out[table][id] = v
+			}
+		}
+	}
+	for id, v := range linked {
+		if v["id"] != nil {
+			out["block"][id] = v
+		}
+	}
+	return out
+}
+
+func (c *SiteBuildCmd) frontMatter(client *notionclient.Client, tree *notionclient.Tree, p *site.Page, users map[string]notionclient.User) site.FrontMatter {
+	block := tree.Block(p.ID)
+	fm := site.FrontMatter{
+		Title:     p.Title,
+		Date:      notionclient.MillisToISO8601(block["created_time"]),
+		Lastmod:   notionclient.MillisToISO8601(block["last_edited_time"]),
+		Weight:    p.Weight,
+		NotionID:  p.ID,
+		NotionURL: client.PageURL(p.ID),
+	}
+	for _, key := range []string{"created_by_id", "last_edited_by_id"} {
+		id, _ := block[key].(string)
+		if name := users[id].Name; name != "" {
+			fm.Authors = append(fm.Authors, name)
+		}
+	}
+	fm.Authors = uniqueStrings(fm.Authors)
+
+	if !p.Row {
+		return fm
+	}
+	cid, _ := block["parent_id"].(string)
+	schema, _ := tree.Records["collection"][cid]["schema"].(map[string]any)
+	props, _ := block["properties"].(map[string]any)
+	for _, key := range notionclient.SortedKeys(schema) {
+		prop, _ := schema[key].(map[string]any)
+		name, _ := prop["name"].(string)
+		typ, _ := prop["type"].(string)
+		if len(c.TagsProperty) > 0 {
+			if !containsFold(c.TagsProperty, name) || (typ != "multi_select" && typ != "select") {
+				continue
+			}
+		} else if typ != "multi_select" {
+			continue
+		}
+		for _, tag := range strings.Split(notionclient.PlainText(props[key]), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				fm.Tags = append(fm.Tags, tag)
+			}
+		}
+	}
+	fm.Tags = uniqueStrings(fm.Tags)
+	return fm
+}
+
+// removeSiteFile deletes a file of a previous build and the directories it
+// leaves empty, up to dir.
+func removeSiteFile(dir string, rel string) bool {
+	dest := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.Remove(dest); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		return false
+	}
+	clean := filepath.Clean(dir)
+	for d := filepath.Dir(dest); d != clean && strings.HasPrefix(d, clean); d = filepath.Dir(d) {
+		if os.Remove(d) != nil {
+			break
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+type SpaceCmd struct {
+	List  SpaceListCmd  `cmd:"" help:"List the workspaces of the logged-in users"`
+	Pages SpacePagesCmd `cmd:"" help:"Show a workspace's top-level sidebar pages"`
+}
+
+type SpaceListCmd struct {
+	JSON bool `name:"json" help:"Emit the space list as JSON"`
+}
+
+type SpacePagesCmd struct {
+	Space string `arg:"" name:"space" help:"Space ID"`
+	JSON  bool   `name:"json" help:"Emit the sidebar as JSON"`
+}
+
+type spaceSummary struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Plan    string   `json:"plan,omitempty"`
+	Members int      `json:"members"`
+	UserIDs []string `json:"user_ids"`
+}
+
+type sidebarPage struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Type  string `json:"type,omitempty"`
+}
+
+type sidebarTeam struct {
+	ID    string        `json:"id"`
+	Name  string        `json:"name"`
+	Pages []sidebarPage `json:"pages"`
+}
+
+type sidebar struct {
+	SpaceID    string        `json:"space_id"`
+	Name       string        `json:"name"`
+	Teamspaces []sidebarTeam `json:"teamspaces"`
+	Shared     []sidebarPage `json:"shared"`
+	Private    []sidebarPage `json:"private"`
+}
+
+func (c *SpaceListCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	resp, err := client.GetSpaces(ctx)
+	if err != nil {
+		return fmt.Errorf("load spaces: %w", err)
+	}
+
+	byID := map[string]*spaceSummary{}
+	for _, userID := range notionclient.SortedKeys(resp) {
+		tables, _ := resp[userID].(map[string]any)
+		flat := notionclient.FlattenRecordMap(map[string]any{"recordMap": tables})
+		for _, id := range notionclient.SortedKeys(flat["space"]) {
+			if s, ok := byID[id]; ok {
+				s.UserIDs = append(s.UserIDs, userID)
+				continue
+			}
+			space := flat["space"][id]
+			name, _ := space["name"].(string)
+			plan, _ := space["plan_type"].(string)
+			byID[id] = &spaceSummary{ID: id, Name: name, Plan: plan, Members: memberCount(space), UserIDs: []string{userID}}
+		}
+	}
+
+	spaces := make([]spaceSummary, 0, len(byID))
+	for _, id := range notionclient.SortedKeys(byID) {
+		spaces = append(spaces, *byID[id])
+	}
+	if c.JSON {
+		return writeOutput(ctx, "", map[string]any{"spaces": spaces})
+	}
+	if len(spaces) == 0 {
+		fmt.Println("no spaces")
+		return nil
+	}
+	for _, s := range spaces {
+		fmt.Printf("%s  %s  plan=%s  members=%d\n", s.ID, titleOr(s.Name), orDash(s.Plan), s.Members)
+	}
+	return nil
+}
+
+// memberCount counts the distinct users granted access in a space's
+// permissions.
+func memberCount(space map[string]any) int {
+	perms, _ := space["permissions"].([]any)
+	users := map[string]bool{}
+	for _, p := range perms {
+		m, _ := p.(map[string]any)
+		if typ, _ := m["type"].(string); typ != "user_permission" {
+			continue
+		}
+		if id, _ := m["user_id"].(string); id != "" {
+			users[id] = true
+		}
+	}
+	return len(users)
+}
+
+func (c *SpacePagesCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+
+	spaceID, err := notionclient.ParsePageID(c.Space)
+	if err != nil {
+		return fmt.Errorf("parse space id: %w", err)
+	}
+	client = client.ForSpace(ctx, spaceID)
+
+	resp, err := client.GetSpaces(ctx)
+	if err != nil {
+		return fmt.Errorf("load spaces: %w", err)
+	}
+	flat := notionclient.FlattenSpaces(resp)
+	space := flat["space"][spaceID]
+	if len(space) == 0 {
+		return fmt.Errorf("space %s not found among the logged-in users' spaces", spaceID)
+	}
+	name, _ := space["name"].(string)
+
+	// Joined teamspaces and private pages come from the acting user's space
+	// view; other logged-in users' views of the same space are not theirs.
+	views := flat["space_view"]
+	if tables, ok := resp[client.CurrentUserID()].(map[string]any); ok {
+		views = notionclient.FlattenRecordMap(map[string]any{"recordMap": tables})["space_view"]
+	}
+	teamIDs := make([]string, 0)
+	privateIDs := make([]string, 0)
+	for _, id := range notionclient.SortedKeys(views) {
+		view := views[id]
+		if sid, _ := view["space_id"].(string); sid != spaceID {
+			continue
+		}
+		teamIDs = append(teamIDs, notionclient.StringList(view["joined_teams"])...)
+		privateIDs = append(privateIDs, notionclient.StringList(view["private_pages"])...)
+	}
+	teamIDs = append(teamIDs, notionclient.StringList(space["teams"])...)
+	teamIDs = uniqueStrings(teamIDs)
+
+	teams := flat["team"]
+	if missing := missingKeys(teams, teamIDs); len(missing) > 0 {
+		fetched, err := client.GetRecords(ctx, "team", missing)
+		if err != nil {
+			return fmt.Errorf("fetch teamspaces: %w", err)
+		}
+		if teams == nil {
+			teams = map[string]map[string]any{}
+		}
+		for id, row := range fetched {
+			teams[id] = row
+		}
+	}
+
+	pageIDs := append([]string{}, privateIDs...)
+	pageIDs = append(pageIDs, notionclient.StringList(space["pages"])...)
+	for _, id := range teamIDs {
+		pageIDs = append(pageIDs, notionclient.StringList(teams[id]["team_pages"])...)
+	}
+	blocks, err := client.GetRecords(ctx, "block", uniqueStrings(pageIDs))
+	if err != nil {
+		return fmt.Errorf("fetch sidebar pages: %w", err)
+	}
+
+	out := sidebar{SpaceID: spaceID, Name: name, Teamspaces: []sidebarTeam{}}
+	listed := map[string]bool{}
+	for _, id := range teamIDs {
+		team := teams[id]
+		if team == nil {
+			continue
+		}
+		teamName, _ := team["name"].(string)
+		out.Teamspaces = append(out.Teamspaces, sidebarTeam{ID: id, Name: titleOr(teamName), Pages: sidebarPages(blocks, notionclient.StringList(team["team_pages"]), listed)})
+	}
+	out.Shared = sidebarPages(blocks, notionclient.StringList(space["pages"]), listed)
+	out.Private = sidebarPages(blocks, privateIDs, listed)
+
+	if c.JSON {
+		return writeOutput(ctx, "", out)
+	}
+	fmt.Printf("%s (%s)\n", titleOr(out.Name), out.SpaceID)
+	for _, t := range out.Teamspaces {
+		fmt.Printf("Teamspace: %s\n", t.Name)
+		printSidebarPages(t.Pages)
+	}
+	fmt.Println("Shared")
+	printSidebarPages(out.Shared)
+	fmt.Println("Private")
+	printSidebarPages(out.Private)
+	return nil
+}
+
+// sidebarPages resolves ids to live pages, skipping those already listed in
+// an earlier section.
+func sidebarPages(blocks map[string]map[string]any, ids []string, listed map[string]bool) []sidebarPage {
+	out := make([]sidebarPage, 0, len(ids))
+	for _, id := range ids {
+		block := blocks[id]
+		if listed[id] || block == nil {
+			continue
+		}
+		if alive, ok := block["alive"].(bool); ok && !alive {
+			continue
+		}
+		listed[id] = true
+		typ, _ := block["type"].(string)
+		out = append(out, sidebarPage{ID: id, Title: titleOr(notionclient.BlockTitle(block)), Type: typ})
+	}
+	return out
+}
+
+func printSidebarPages(pages []sidebarPage) {
+	if len(pages) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, p := range pages {
+		fmt.Printf("  - %s  %s\n", p.Title, p.ID)
+	}
+}
+
+func missingKeys(m map[string]map[string]any, ids []string) []string {
+	out := make([]string, 0)
+	for _, id := range ids {
+		if _, ok := m[id]; !ok {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func uniqueStrings(in []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jodok/nocli/internal/notionclient"
+	"github.com/jodok/nocli/internal/watch"
+)
+
+type WatchCmd struct {
+	Target   string        `arg:"" help:"Page or database URL/ID to watch" name:"page-or-database"`
+	Interval time.Duration `name:"interval" default:"30s" help:"Time between polls"`
+	Polls    int           `name:"polls" default:"0" help:"Stop after this many polls (0 = until interrupted)"`
+	RowLimit int           `name:"row-limit" default:"1000" help:"Maximum rows fetched per database"`
+	State    string        `name:"state" help:"Keep the last seen records and undelivered events in this file and resume from it"`
+
+	Webhook        string `name:"webhook" help:"POST each event as JSON to this URL"`
+	WebhookSecret  string `name:"webhook-secret" env:"NOCLI_WEBHOOK_SECRET" help:"HMAC-SHA256 key for the X-Nocli-Signature header (required with --webhook)"`
+	WebhookRetries int    `name:"webhook-retries" default:"2" help:"Retries of a failed delivery before it is left pending until the next poll"`
+	DeadLetter     string `name:"dead-letter" default:"nocli-webhook-dead.ndjson" help:"Append events the webhook rejected with a 4xx response to this file"`
+}
+
+func (c *WatchCmd) Run(ctx context.Context) error {
+	client := ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("internal error: notion client missing from context")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	var hook *watch.Webhook
+	if strings.TrimSpace(c.Webhook) != "" {
+		if c.WebhookSecret == "" {
+			return fmt.Errorf("--webhook needs --webhook-secret (or NOCLI_WEBHOOK_SECRET) to sign deliveries")
+		}
+		if c.State == "" {
+			return fmt.Errorf("--webhook needs --state to keep undelivered events across restarts")
+		}
+		hook = &watch.Webhook{URL: c.Webhook, Secret: c.WebhookSecret, Retries: c.WebhookRetries}
+	}
+
+	id, err := notionclient.ParsePageID(c.Target)
+	if err != nil {
+		return err
+	}
+	root, err := fetchBlock(ctx, client, id)
+	if err != nil {
+		return err
+	}
+	if spaceID, _ := root["space_id"].(string); spaceID != "" {
+		client = client.ForSpace(ctx, spaceID)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := watch.New(client, id)
+	w.RowLimit = c.RowLimit
+	pending := make([]watch.Event, 0)
+	resumed := false
+	if c.State != "" {
+		st, ok, err := watch.LoadState(c.State)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := w.Restore(st); err != nil {
+				return fmt.Errorf("%s: %w", c.State, err)
+			}
+			pending = append(pending, st.Pending...)
+			resumed = true
+			_, _ = fmt.Fprintf(os.Stderr, "resuming from %s (saved %s, %d undelivered events)\n", c.State, st.SavedAt, len(pending))
+		}
+	}
+	polls := 0
+	if !resumed {
+		if _, err := w.Poll(ctx); err != nil {
+			return err
+		}
+		polls++
+		if err := c.saveState(w, pending); err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "watching %d records of %s every %s\n", w.Tracked(), id, c.Interval)
+
+	pending, err = c.deliver(ctx, hook, w, pending)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	// After resuming, poll right away to pick up what changed while stopped.
+	wait := !resumed
+	for ; c.Polls <= 0 || polls < c.Polls; polls++ {
+		if wait {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(c.Interval):
+			}
+		}
+		wait = true
+
+		events, err := w.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			// Keep watching through transient failures; the next poll
+			// reports everything that changed in the meantime.
+			_, _ = fmt.Fprintf(os.Stderr, "warning: poll failed: %v\n", err)
+			continue
+		}
+		for _, ev := range events {
+			if err := enc.Encode(ev); err != nil {
+				return fmt.Errorf("write event: %w", err)
+			}
+		}
+		if hook != nil {
+			pending = append(pending, events...)
+		}
+		// Save the new baseline together with the undelivered events before
+		// delivering, so a crash re-sends them instead of losing them.
+		if err := c.saveState(w, pending); err != nil {
+			return err
+		}
+		if pending, err = c.deliver(ctx, hook, w, pending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver posts pending events in order. When the webhook is unreachable or
+// answers with a retryable status, delivery stops there and that event and
+// the ones after it stay pending for the next poll. Events rejected with any
+// other 4xx go to the dead-letter file. It returns the events still
+// undelivered.
+func (c *WatchCmd) deliver(ctx context.Context, hook *watch.Webhook, w *watch.Watcher, pending []watch.Event) ([]watch.Event, error) {
+	if hook == nil || len(pending) == 0 {
+		return pending, nil
+	}
+	for i, ev := range pending {
+		err := hook.Deliver(ctx, ev)
+		if err == nil {
+			continue
+		}
+		var deliveryErr *watch.DeliveryError
+		if ctx.Err() != nil || (errors.As(err, &deliveryErr) && deliveryErr.Retryable) {
+			rest := pending[i:]
+			if ctx.Err() == nil {
+				_, _ = fmt.Fprintf(os.Stderr, "warning: %v; %d event(s) left pending until the next poll\n", err, len(rest))
+			}
+			return rest, c.saveState(w, rest)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %v; writing to %s\n", err, c.DeadLetter)
+		if err := watch.AppendDeadLetter(c.DeadLetter, ev, err); err != nil {
+			return pending[i:], err
+		}
+	}
+	return pending[:0], c.saveState(w, nil)
+}
+
+func (c *WatchCmd) saveState(w *watch.Watcher, pending []watch.Event) error {
+	if c.State == "" {
+		return nil
+	}
+	st := w.State()
+	st.Pending = append(st.Pending, pending...)
+	return watch.SaveState(c.State, st)
+}
@@ -5,15 +5,101 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
-type File struct {
+// Profile holds one set of Notion credentials.
+type Profile struct {
 	BaseURL      string `json:"base_url,omitempty"`
 	TokenV2      string `json:"token_v2,omitempty"`
 	NotionUserID string `json:"notion_user_id,omitempty"`
 	ActiveUserID string `json:"active_user_id,omitempty"`
 	Cookie       string `json:"cookie,omitempty"`
+	// Encrypted holds token_v2 and cookie as an ASCII-armored age
+	// (scrypt passphrase) ciphertext; see Seal and Unlock.
+	Encrypted string `json:"encrypted,omitempty"`
+	// CredentialHelper is a command that stores and returns token_v2 and
+	// cookie instead of the config file.
+	CredentialHelper string `json:"credential_helper,omitempty"`
+}
+
+// File is the on-disk config. The embedded Profile is the unnamed default
+// profile, which keeps single-profile config files from before named
+// profiles working unchanged.
+type File struct {
+	Profile
+	DefaultProfile string             `json:"default_profile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+}
+
+// DefaultProfileName addresses the top-level (unnamed) profile.
+const DefaultProfileName = "default"
+
+// ProfileName resolves which profile to use: the explicit name, else
+// default_profile, else the top-level profile.
+func (f File) ProfileName(name string) string {
+	if n := strings.TrimSpace(name); n != "" {
+		return n
+	}
+	if n := strings.TrimSpace(f.DefaultProfile); n != "" {
+		return n
+	}
+	return DefaultProfileName
+}
+
+// Lookup returns the named profile. The name "default" refers to the
+// top-level profile unless a named profile called "default" exists.
+func (f File) Lookup(name string) (Profile, bool) {
+	if p, ok := f.Profiles[name]; ok {
+		return p, true
+	}
+	if name == DefaultProfileName {
+		return f.Profile, true
+	}
+	return Profile{}, false
+}
+
+// SetProfile stores p under name, writing the top-level profile for "default".
+func (f *File) SetProfile(name string, p Profile) {
+	if _, ok := f.Profiles[name]; !ok && name == DefaultProfileName {
+		f.Profile = p
+		return
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]Profile{}
+	}
+	f.Profiles[name] = p
+}
+
+// RemoveProfile deletes a profile and clears default_profile if it pointed
+// at it. It reports whether the profile existed.
+func (f *File) RemoveProfile(name string) bool {
+	if _, ok := f.Profiles[name]; ok {
+		delete(f.Profiles, name)
+	} else if name == DefaultProfileName && f.Profile != (Profile{}) {
+		f.Profile = Profile{}
+	} else {
+		return false
+	}
+	if f.DefaultProfile == name {
+		f.DefaultProfile = ""
+	}
+	return true
+}
+
+// ProfileNames lists all configured profiles, including "default" when the
+// top-level profile holds credentials.
+func (f File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles)+1)
+	if _, named := f.Profiles[DefaultProfileName]; !named && f.Profile != (Profile{}) {
+		names = append(names, DefaultProfileName)
+	}
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func Read(path string) (File, error) {
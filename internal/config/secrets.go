@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// PassphraseFunc supplies the passphrase for encrypted profiles. It is only
+// called when a passphrase is actually needed.
+type PassphraseFunc func() (string, error)
+
+// secrets are the profile fields kept out of plaintext config when a profile
+// is encrypted or delegated to a credential helper.
+type secrets struct {
+	TokenV2 string `json:"token_v2,omitempty"`
+	Cookie  string `json:"cookie,omitempty"`
+}
+
+// Locked reports whether the profile's secrets live outside the plaintext
+// fields and need Unlock before use.
+func (p Profile) Locked() bool {
+	return strings.TrimSpace(p.Encrypted) != "" || strings.TrimSpace(p.CredentialHelper) != ""
+}
+
+// Unlock returns p with token_v2 and cookie filled in from the encrypted
+// blob or the credential helper.
+func Unlock(name string, p Profile, passphrase PassphraseFunc) (Profile, error) {
+	var s secrets
+	switch {
+	case strings.TrimSpace(p.CredentialHelper) != "":
+		out, err := runCredentialHelper(p.CredentialHelper, "get", name, nil)
+		if err != nil {
+			return p, err
+		}
+		if err := json.Unmarshal(out, &s); err != nil {
+			return p, fmt.Errorf("parse credential helper output: %w", err)
+		}
+	case strings.TrimSpace(p.Encrypted) != "":
+		pass, err := passphrase()
+		if err != nil {
+			return p, err
+		}
+		id, err := age.NewScryptIdentity(pass)
+		if err != nil {
+			return p, fmt.Errorf("create scrypt identity: %w", err)
+		}
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(p.Encrypted)), id)
+		if err != nil {
+			return p, fmt.Errorf("decrypt profile %q (wrong passphrase?): %w", name, err)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return p, fmt.Errorf("decrypt profile %q: %w", name, err)
+		}
+		if err := json.Unmarshal(b, &s); err != nil {
+			return p, fmt.Errorf("parse decrypted profile %q: %w", name, err)
+		}
+	default:
+		return p, nil
+	}
+
+	p.TokenV2 = s.TokenV2
+	p.Cookie = s.Cookie
+	return p, nil
+}
+
+// Seal moves token_v2 and cookie out of the plaintext fields: to the
+// credential helper when one is configured, otherwise into an age
+// scrypt-encrypted blob.
+func Seal(name string, p Profile, passphrase PassphraseFunc) (Profile, error) {
+	s := secrets{TokenV2: p.TokenV2, Cookie: p.Cookie}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return p, fmt.Errorf("marshal secrets: %w", err)
+	}
+
+	if strings.TrimSpace(p.CredentialHelper) != "" {
+		if _, err := runCredentialHelper(p.CredentialHelper, "store", name, b); err != nil {
+			return p, err
+		}
+		p.Encrypted = ""
+	} else {
+		pass, err := passphrase()
+		if err != nil {
+			return p, err
+		}
+		rcpt, err := age.NewScryptRecipient(pass)
+		if err != nil {
+			return p, fmt.Errorf("create scrypt recipient: %w", err)
+		}
+		var buf bytes.Buffer
+		aw := armor.NewWriter(&buf)
+		w, err := age.Encrypt(aw, rcpt)
+		if err != nil {
+			return p, fmt.Errorf("encrypt profile %q: %w", name, err)
+		}
+		if _, err := w.Write(b); err != nil {
+			return p, fmt.Errorf("encrypt profile %q: %w", name, err)
+		}
+		if err := w.Close(); err != nil {
+			return p, fmt.Errorf("encrypt profile %q: %w", name, err)
+		}
+		if err := aw.Close(); err != nil {
+			return p, fmt.Errorf("encrypt profile %q: %w", name, err)
+		}
+		p.Encrypted = buf.String()
+	}
+
+	p.TokenV2 = ""
+	p.Cookie = ""
+	return p, nil
+}
+
+// runCredentialHelper runs `<helper> <action> <profile>` through the shell,
+// git-credential style. "get" prints {"token_v2": ..., "cookie": ...} on
+// stdout; "store" receives the same JSON on stdin.
+func runCredentialHelper(helper string, action string, profile string, stdin []byte) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", helper+` "$@"`, "sh", action, profile)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %s %s: %w", action, profile, err)
+	}
+	return out, nil
+}
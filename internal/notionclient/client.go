@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -20,7 +21,10 @@ type Options struct {
 	NotionUserID string
 	ActiveUserID string
 	Cookie       string
-	HTTPClient   *http.Client
+	// PinActiveUser keeps ActiveUserID for every request instead of
+	// switching to the user that owns the targeted space.
+	PinActiveUser bool
+	HTTPClient    *http.Client
 }
 
 type Client struct {
@@ -30,6 +34,9 @@ type Client struct {
 	notionUserID string
 	activeUserID string
 	cookie       string
+
+	pinnedActiveUser bool
+	spaceUsers       *spaceUserCache
 }
 
 func New(opts Options) (*Client, error) {
@@ -55,6 +62,9 @@ func New(opts Options) (*Client, error) {
 		notionUserID: strings.TrimSpace(opts.NotionUserID),
 		activeUserID: strings.TrimSpace(opts.ActiveUserID),
 		cookie:       strings.TrimSpace(opts.Cookie),
+
+		pinnedActiveUser: opts.PinActiveUser,
+		spaceUsers:       &spaceUserCache{},
 	}, nil
 }
 
@@ -70,9 +80,39 @@ func (c *Client) postJSON(ctx context.Context, endpoint string, payload any) (ma
 		return nil, fmt.Errorf("marshal request payload: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	var resp *http.Response
+	var respBody []byte
+	for attempt := 0; ; attempt++ {
+		resp, respBody, err = c.doPost(ctx, u.String(), body)
+		if err != nil {
+			return nil, err
+		}
+		if !retryableStatus(rel.Path, resp.StatusCode) || attempt >= maxRetries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retryDelay(resp, attempt)):
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &APIError{Endpoint: rel.Path, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(respBody))}
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return nil, fmt.Errorf("decode response json: %w", err)
+	}
+
+	return out, nil
+}
+
+func (c *Client) doPost(ctx context.Context, u string, body []byte) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, nil, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json")
@@ -91,25 +131,98 @@ func (c *Client) postJSON(ctx context.Context, endpoint string, payload any) (ma
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		return nil, nil, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response body: %w", err)
+		return nil, nil, fmt.Errorf("read response body: %w", err)
 	}
+	return resp, respBody, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("notion request failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+// maxRetries is how often rate-limited or temporarily failing requests are
+// retried before the error is returned.
+const maxRetries = 4
+
+// readEndpoints only read. Any other endpoint, including those sent through
+// Post, may change state, and a 502/504 can arrive after the server already
+// applied the request, so they are only retried on 429, which is rejected
+// before anything runs.
+var readEndpoints = map[string]bool{
+	"/api/v3/syncRecordValuesMain":  true,
+	"/api/v3/getRecordValues":       true,
+	"/api/v3/loadPageChunk":         true,
+	"/api/v3/loadCachedPageChunkV2": true,
+	"/api/v3/queryCollection":       true,
+	"/api/v3/search":                true,
+	"/api/v3/getBacklinksForBlock":  true,
+	"/api/v3/getSnapshotsList":      true,
+	"/api/v3/getSnapshotContents":   true,
+	"/api/v3/loadUserContent":       true,
+	"/api/v3/getSpaces":             true,
+	"/api/v3/getSignedFileUrls":     true,
+	"/api/v3/getTasks":              true,
+	"/api/v3/findUser":              true,
+}
+
+func retryableStatus(endpoint string, code int) bool {
+	switch code {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return readEndpoints[endpoint]
 	}
+	return false
+}
 
-	var out map[string]any
-	if err := json.Unmarshal(respBody, &out); err != nil {
-		return nil, fmt.Errorf("decode response json: %w", err)
+// retryDelay honours a Retry-After header in seconds, otherwise backs off
+// exponentially from 500ms.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if secs, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, 30*time.Second)
 	}
+	return 500 * time.Millisecond << attempt
+}
 
-	return out, nil
+// Post sends payload to an arbitrary /api/v3/ endpoint with the client's
+// credentials and returns the decoded response.
+func (c *Client) Post(ctx context.Context, endpoint string, payload any) (map[string]any, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if !strings.HasPrefix(endpoint, "/") {
+		endpoint = "/api/v3/" + endpoint
+	}
+	if !strings.HasPrefix(endpoint, "/api/v3/") || strings.Contains(endpoint, "..") {
+		return nil, fmt.Errorf("endpoint %q is not under /api/v3/", endpoint)
+	}
+	return c.postJSON(ctx, endpoint, payload)
+}
+
+// ActiveUserID returns the configured x-notion-active-user-header value.
+func (c *Client) ActiveUserID() string {
+	return c.activeUserID
+}
+
+// HasCredentials reports whether any session cookie material is configured.
+func (c *Client) HasCredentials() bool {
+	return c.cookieHeader() != ""
+}
+
+// APIError is returned for non-2xx responses from Notion endpoints.
+type APIError struct {
+	Endpoint   string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("notion request failed: status=%d body=%s", e.StatusCode, e.Body)
+}
+
+// Unauthorized reports whether Notion rejected the session credentials.
+func (e *APIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || strings.Contains(e.Body, "UnauthorizedError")
 }
 
 func (c *Client) cookieHeader() string {
@@ -126,3 +239,46 @@ func (c *Client) cookieHeader() string {
 	}
 	return strings.Join(parts, "; ")
 }
+
+// ParseCookieValue returns the value of key in a Cookie header, or "".
+func ParseCookieValue(cookieHeader string, key string) string {
+	for _, p := range splitCookieParts(cookieHeader) {
+		k, v, ok := splitKV(p)
+		if !ok {
+			continue
+		}
+		if k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+func splitCookieParts(cookie string) []string {
+	parts := make([]string, 0)
+	cur := ""
+	for _, r := range cookie {
+		if r == ';' {
+			parts = append(parts, cur)
+			cur = ""
+			continue
+		}
+		cur += string(r)
+	}
+	parts = append(parts, cur)
+	return parts
+}
+
+func splitKV(s string) (string, string, bool) {
+	for i, r := range s {
+		if r == '=' {
+			k := strings.TrimSpace(s[:i])
+			v := strings.TrimSpace(s[i+1:])
+			if k == "" {
+				return "", "", false
+			}
+			return k, v, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,42 @@
+package notionclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPostRetries(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		status   int
+		want     int
+	}{
+		{"/api/v3/syncRecordValuesMain", http.StatusBadGateway, maxRetries + 1},
+		{"/api/v3/syncRecordValuesMain", http.StatusTooManyRequests, maxRetries + 1},
+		{"/api/v3/saveTransactionsFanout", http.StatusGatewayTimeout, 1},
+		{"/api/v3/saveTransactionsFanout", http.StatusTooManyRequests, maxRetries + 1},
+		{"/api/v3/deleteBlocks", http.StatusServiceUnavailable, 1},
+		{"/api/v3/getSpaces", http.StatusInternalServerError, 1},
+	}
+	for _, tt := range tests {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(tt.status)
+		}))
+		c, err := New(Options{BaseURL: srv.URL, TokenV2: "tok"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Post(context.Background(), tt.endpoint, map[string]any{}); err == nil {
+			t.Errorf("%s %d: no error", tt.endpoint, tt.status)
+		}
+		if calls != tt.want {
+			t.Errorf("%s %d: %d request(s), want %d", tt.endpoint, tt.status, calls, tt.want)
+		}
+		srv.Close()
+	}
+}
@@ -1,6 +1,8 @@
 package notionclient
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"regexp"
 	"strings"
@@ -32,3 +34,14 @@ func formatUUID(compact string) string {
 		compact[16:20] + "-" +
 		compact[20:32]
 }
+
+// NewID returns a random version 4 UUID in Notion's dashed record ID format.
+func NewID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate record id: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return formatUUID(hex.EncodeToString(b[:])), nil
+}
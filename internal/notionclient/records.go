@@ -78,6 +78,13 @@ func SortedKeys[M ~map[string]V, V any](m M) []string {
 	return keys
 }
 
+// IsAlive reports whether a record has not been deleted. Records without an
+// alive field count as alive.
+func IsAlive(record map[string]any) bool {
+	v, ok := record["alive"].(bool)
+	return !ok || v
+}
+
 func ToPartialUserObject(userID string) map[string]any {
 	if strings.TrimSpace(userID) == "" {
 		return nil
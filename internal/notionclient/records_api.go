@@ -5,7 +5,7 @@ import "context"
 type syncRequest struct {
 	Table   string `json:"table"`
 	ID      string `json:"id"`
-	Version int    `json:"version"`
+	Version int64  `json:"version"`
 }
 
 type syncRecordValuesRequest struct {
@@ -24,9 +24,28 @@ type queryCollectionRequest struct {
 }
 
 func (c *Client) SyncBlockRecords(ctx context.Context, blockIDs []string) (map[string]any, error) {
-	reqs := make([]syncRequest, 0, len(blockIDs))
-	for _, id := range blockIDs {
-		reqs = append(reqs, syncRequest{Table: "block", ID: id, Version: -1})
+	return c.SyncRecords(ctx, "block", blockIDs)
+}
+
+func (c *Client) SyncRecords(ctx context.Context, table string, ids []string) (map[string]any, error) {
+	reqs := make([]syncRequest, 0, len(ids))
+	for _, id := range ids {
+		reqs = append(reqs, syncRequest{Table: table, ID: id, Version: -1})
+	}
+	return c.postJSON(ctx, "/api/v3/syncRecordValuesMain", syncRecordValuesRequest{Requests: reqs})
+}
+
+// SyncRecordVersions is SyncRecords for records the caller already has:
+// versions holds the known version of each ID (-1 when unknown), and Notion
+// leaves records still at that version out of the response.
+func (c *Client) SyncRecordVersions(ctx context.Context, table string, ids []string, versions map[string]int64) (map[string]any, error) {
+	reqs := make([]syncRequest, 0, len(ids))
+	for _, id := range ids {
+		version, ok := versions[id]
+		if !ok {
+			version = -1
+		}
+		reqs = append(reqs, syncRequest{Table: table, ID: id, Version: version})
 	}
 	return c.postJSON(ctx, "/api/v3/syncRecordValuesMain", syncRecordValuesRequest{Requests: reqs})
 }
@@ -67,3 +86,21 @@ func (c *Client) QueryCollection(ctx context.Context, collectionID string, viewI
 	}
 	return c.postJSON(ctx, "/api/v3/queryCollection?src=initial_load", payload)
 }
+
+// CollectionRowIDs extracts the row block IDs from a queryCollection response.
+func CollectionRowIDs(resp map[string]any) []string {
+	result, _ := resp["result"].(map[string]any)
+	reducers, _ := result["reducerResults"].(map[string]any)
+	group, _ := reducers["collection_group_results"].(map[string]any)
+	raw, _ := group["blockIds"].([]any)
+	if len(raw) == 0 {
+		raw, _ = result["blockIds"].([]any)
+	}
+	ids := make([]string, 0, len(raw))
+	for _, x := range raw {
+		if s, _ := x.(string); s != "" {
+			ids = append(ids, s)
+		}
+	}
+	return ids
+}
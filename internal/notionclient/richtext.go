@@ -0,0 +1,92 @@
+package notionclient
+
+import "strings"
+
+// PlainText concatenates the text segments of a private-API rich text value
+// ([["text", [decorations...]], ...]).
+func PlainText(v any) string {
+	segments, _ := v.([]any)
+	var b strings.Builder
+	for _, seg := range segments {
+		parts, _ := seg.([]any)
+		if len(parts) == 0 {
+			continue
+		}
+		if s, ok := parts[0].(string); ok {
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+// BlockTitle returns the plain-text title property of a block.
+func BlockTitle(block map[string]any) string {
+	props, _ := block["properties"].(map[string]any)
+	return PlainText(props["title"])
+}
+
+// TextSegment returns one rich text segment, optionally decorated (e.g.
+// []any{"b"} or []any{"a", url}).
+func TextSegment(text string, decorations ...[]any) []any {
+	if len(decorations) == 0 {
+		return []any{text}
+	}
+	decs := make([]any, 0, len(decorations))
+	for _, d := range decorations {
+		decs = append(decs, d)
+	}
+	return []any{text, decs}
+}
+
+// UserMention returns an inline mention of a user.
+func UserMention(userID string) []any {
+	return TextSegment("‣", []any{"u", userID})
+}
+
+// PageMention returns an inline mention of a page.
+func PageMention(pageID string) []any {
+	return TextSegment("‣", []any{"p", pageID})
+}
+
+// DateMention returns an inline date mention for a YYYY-MM-DD date.
+func DateMention(date string) []any {
+	return TextSegment("‣", []any{"d", map[string]any{"type": "date", "start_date": date}})
+}
+
+// MentionedPageIDs returns the targets of page mentions (["p", id]) in rich
+// text, in order.
+func MentionedPageIDs(v any) []string {
+	out := make([]string, 0)
+	segments, _ := v.([]any)
+	for _, seg := range segments {
+		parts, _ := seg.([]any)
+		if len(parts) < 2 {
+			continue
+		}
+		decorations, _ := parts[1].([]any)
+		for _, d := range decorations {
+			dec, _ := d.([]any)
+			if len(dec) < 2 {
+				continue
+			}
+			if tag, _ := dec[0].(string); tag == "p" {
+				if id, _ := dec[1].(string); id != "" {
+					out = append(out, id)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// AliasTarget returns the page a link-to-page block (type "alias") points
+// to, or "" for other blocks.
+func AliasTarget(block map[string]any) string {
+	if typ, _ := block["type"].(string); typ != "alias" {
+		return ""
+	}
+	format, _ := block["format"].(map[string]any)
+	pointer, _ := format["alias_pointer"].(map[string]any)
+	id, _ := pointer["id"].(string)
+	return id
+}
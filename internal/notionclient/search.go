@@ -0,0 +1,118 @@
+package notionclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SearchOptions are the filters of a BlocksInSpace search.
+type SearchOptions struct {
+	Query     string
+	SpaceID   string
+	Limit     int
+	CreatedBy []string
+	// EditedAfter is a YYYY-MM-DD date; results edited before it are dropped
+	// by Notion.
+	EditedAfter string
+}
+
+// Search runs a quick-find search in one space and returns the raw response,
+// which carries the matching IDs in results and their records in recordMap.
+func (c *Client) Search(ctx context.Context, opts SearchOptions) (map[string]any, error) {
+	if strings.TrimSpace(opts.SpaceID) == "" {
+		return nil, fmt.Errorf("search: space id is empty")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	createdBy := opts.CreatedBy
+	if createdBy == nil {
+		createdBy = []string{}
+	}
+	lastEdited := map[string]any{}
+	if opts.EditedAfter != "" {
+		lastEdited["starting"] = map[string]any{"type": "date", "start_date": opts.EditedAfter}
+	}
+
+	payload := map[string]any{
+		"type":    "BlocksInSpace",
+		"query":   opts.Query,
+		"spaceId": opts.SpaceID,
+		"limit":   limit,
+		"filters": map[string]any{
+			"isDeletedOnly":             false,
+			"excludeTemplates":          false,
+			"navigableBlockContentOnly": true,
+			"requireEditPermissions":    false,
+			"ancestors":                 []string{},
+			"createdBy":                 createdBy,
+			"editedBy":                  []string{},
+			"lastEditedTime":            lastEdited,
+			"createdTime":               map[string]any{},
+			"inTeams":                   []string{},
+		},
+		"sort":   map[string]any{"field": "relevance"},
+		"source": "quick_find_input_change",
+	}
+	return c.ForSpace(ctx, opts.SpaceID).postJSON(ctx, "/api/v3/search", payload)
+}
+
+// SearchResultIDs returns the block IDs of a search response in rank order.
+func SearchResultIDs(resp map[string]any) []string {
+	results, _ := resp["results"].([]any)
+	out := make([]string, 0, len(results))
+	for _, r := range results {
+		m, _ := r.(map[string]any)
+		if id, _ := m["id"].(string); id != "" {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// PageURL returns the web URL of a page on the configured Notion host.
+func (c *Client) PageURL(id string) string {
+	return strings.TrimRight(c.baseURL.String(), "/") + "/" + strings.ReplaceAll(id, "-", "")
+}
+
+// Backlink is a block that mentions or links to another block.
+type Backlink struct {
+	// BlockID is the referencing block.
+	BlockID string
+	// Kind is Notion's mention type, e.g. "block_mention" or
+	// "property_mention".
+	Kind string
+	// PropertyID is set for mentions inside a database property.
+	PropertyID string
+}
+
+// GetBacklinks returns the blocks referencing blockID, plus the recordMap
+// Notion sends along with them.
+func (c *Client) GetBacklinks(ctx context.Context, blockID string, spaceID string) ([]Backlink, map[string]any, error) {
+	resp, err := c.ForSpace(ctx, spaceID).postJSON(ctx, "/api/v3/getBacklinksForBlock", map[string]any{
+		"block": map[string]any{"id": blockID, "spaceId": spaceID},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, _ := resp["backlinks"].([]any)
+	out := make([]Backlink, 0, len(raw))
+	seen := map[string]bool{}
+	for _, r := range raw {
+		m, _ := r.(map[string]any)
+		from, _ := m["mentioned_from"].(map[string]any)
+		bl := Backlink{}
+		bl.BlockID, _ = from["block_id"].(string)
+		bl.Kind, _ = from["type"].(string)
+		bl.PropertyID, _ = from["property_id"].(string)
+		key := bl.BlockID + "/" + bl.PropertyID
+		if bl.BlockID == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, bl)
+	}
+	return out, resp, nil
+}
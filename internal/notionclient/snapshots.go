@@ -0,0 +1,72 @@
+package notionclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Snapshot is one entry of a page's version history.
+type Snapshot struct {
+	ID          string   `json:"id"`
+	Version     int64    `json:"version"`
+	LastVersion int64    `json:"last_version,omitempty"`
+	Timestamp   int64    `json:"timestamp"`
+	Authors     []string `json:"authors"`
+}
+
+// GetSnapshots lists up to size snapshots of a page, newest first.
+func (c *Client) GetSnapshots(ctx context.Context, blockID string, size int) ([]Snapshot, error) {
+	if size <= 0 {
+		size = 100
+	}
+	resp, err := c.postJSON(ctx, "/api/v3/getSnapshotsList", map[string]any{
+		"blockId": blockID,
+		"size":    size,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	raw, _ := resp["snapshots"].([]any)
+	out := make([]Snapshot, 0, len(raw))
+	for _, r := range raw {
+		m, _ := r.(map[string]any)
+		if m == nil {
+			continue
+		}
+		s := Snapshot{Authors: []string{}}
+		s.ID, _ = m["id"].(string)
+		s.Version, _ = parseInt64(m["version"])
+		s.LastVersion, _ = parseInt64(m["lastVersion"])
+		s.Timestamp, _ = parseInt64(m["timestamp"])
+		authors, _ := m["authors"].([]any)
+		for _, a := range authors {
+			am, _ := a.(map[string]any)
+			if id, _ := am["id"].(string); id != "" {
+				s.Authors = append(s.Authors, id)
+			}
+		}
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp > out[j].Timestamp })
+	return out, nil
+}
+
+// GetSnapshotContents returns the recordMap of a page as captured by snap.
+func (c *Client) GetSnapshotContents(ctx context.Context, blockID string, snap Snapshot) (map[string]any, error) {
+	if snap.Timestamp <= 0 {
+		return nil, fmt.Errorf("snapshot has no timestamp")
+	}
+	payload := map[string]any{
+		"blockId":   blockID,
+		"timestamp": snap.Timestamp,
+	}
+	if snap.ID != "" {
+		payload["snapshotId"] = snap.ID
+	}
+	if snap.Version > 0 {
+		payload["version"] = snap.Version
+	}
+	return c.postJSON(ctx, "/api/v3/getSnapshotContents", payload)
+}
@@ -0,0 +1,192 @@
+package notionclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+func (c *Client) LoadUserContent(ctx context.Context) (map[string]any, error) {
+	return c.postJSON(ctx, "/api/v3/loadUserContent", map[string]any{})
+}
+
+// GetSpaces returns the getSpaces response: user ID -> recordMap-like tables
+// for every user logged in with the current cookie.
+func (c *Client) GetSpaces(ctx context.Context) (map[string]any, error) {
+	return c.postJSON(ctx, "/api/v3/getSpaces", map[string]any{})
+}
+
+// FlattenSpaces merges the per-user tables of a getSpaces response into one
+// table -> id -> value map, like FlattenRecordMap.
+func FlattenSpaces(resp map[string]any) map[string]map[string]map[string]any {
+	out := map[string]map[string]map[string]any{}
+	for _, userID := range SortedKeys(resp) {
+		tables, ok := resp[userID].(map[string]any)
+		if !ok {
+			continue
+		}
+		for table, rows := range FlattenRecordMap(map[string]any{"recordMap": tables}) {
+			if out[table] == nil {
+				out[table] = map[string]map[string]any{}
+			}
+			for id, row := range rows {
+				out[table][id] = row
+			}
+		}
+	}
+	return out
+}
+
+// spaceUserCache maps space IDs to the logged-in user that can access them.
+// It is shared by clients derived with ForSpace/WithActiveUser.
+type spaceUserCache struct {
+	mu     sync.Mutex
+	loaded bool
+	users  map[string][]string
+	emails map[string]string
+}
+
+// LoggedInUserIDs returns the user IDs listed in the notion_users cookie,
+// falling back to notion_user_id when the cookie has no such list.
+func (c *Client) LoggedInUserIDs() []string {
+	if raw := ParseCookieValue(c.cookie, "notion_users"); raw != "" {
+		if decoded, err := url.QueryUnescape(raw); err == nil {
+			var ids []string
+			if json.Unmarshal([]byte(decoded), &ids) == nil && len(ids) > 0 {
+				return ids
+			}
+		}
+	}
+	if id := c.notionUserID; id != "" {
+		return []string{id}
+	}
+	if id := ParseCookieValue(c.cookie, "notion_user_id"); id != "" {
+		return []string{id}
+	}
+	return nil
+}
+
+// WithActiveUser returns a client that always sends userID as the active
+// user, regardless of the space a request targets.
+func (c *Client) WithActiveUser(userID string) *Client {
+	cp := *c
+	cp.activeUserID = userID
+	cp.pinnedActiveUser = true
+	return &cp
+}
+
+// ForSpace returns a client whose active user can access spaceID, keeping the
+// current active user when it can. With a single logged-in user, or an
+// explicitly pinned active user, it returns c.
+func (c *Client) ForSpace(ctx context.Context, spaceID string) *Client {
+	if c.pinnedActiveUser || spaceID == "" || len(c.LoggedInUserIDs()) < 2 {
+		return c
+	}
+	if err := c.loadSpaceUsers(ctx); err != nil {
+		return c
+	}
+	c.spaceUsers.mu.Lock()
+	userIDs := c.spaceUsers.users[spaceID]
+	c.spaceUsers.mu.Unlock()
+	if len(userIDs) == 0 {
+		return c
+	}
+	for _, id := range userIDs {
+		if id == c.CurrentUserID() {
+			return c
+		}
+	}
+	cp := *c
+	cp.activeUserID = userIDs[0]
+	return &cp
+}
+
+// ForRecord is ForSpace for the space of a record. When the current user
+// cannot read the record, the other logged-in users are tried in order. With
+// a single logged-in user, or an explicitly pinned active user, it returns c
+// without a request.
+func (c *Client) ForRecord(ctx context.Context, table string, id string) (*Client, error) {
+	if c.pinnedActiveUser || len(c.LoggedInUserIDs()) < 2 {
+		return c, nil
+	}
+	candidates := []*Client{c}
+	for _, userID := range c.LoggedInUserIDs() {
+		if userID != c.CurrentUserID() {
+			cp := *c
+			cp.activeUserID = userID
+			candidates = append(candidates, &cp)
+		}
+	}
+	var lastErr error
+	for _, cand := range candidates {
+		resp, err := cand.SyncRecords(ctx, table, []string{id})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if spaceID, _ := FlattenRecordMap(resp)[table][id]["space_id"].(string); spaceID != "" {
+			return cand.ForSpace(ctx, spaceID), nil
+		}
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("fetch %s %s: %w", table, id, lastErr)
+	}
+	return c, nil
+}
+
+// ResolveUser maps a logged-in user's email address or ID to its user ID.
+func (c *Client) ResolveUser(ctx context.Context, emailOrID string) (string, error) {
+	s := strings.TrimSpace(emailOrID)
+	if !strings.Contains(s, "@") {
+		id, err := ParsePageID(s)
+		if err != nil {
+			return "", fmt.Errorf("parse user id: %w", err)
+		}
+		return id, nil
+	}
+	if err := c.loadSpaceUsers(ctx); err != nil {
+		return "", err
+	}
+	c.spaceUsers.mu.Lock()
+	defer c.spaceUsers.mu.Unlock()
+	if id := c.spaceUsers.emails[strings.ToLower(s)]; id != "" {
+		return id, nil
+	}
+	return "", fmt.Errorf("no logged-in user with email %s", s)
+}
+
+func (c *Client) loadSpaceUsers(ctx context.Context) error {
+	c.spaceUsers.mu.Lock()
+	loaded := c.spaceUsers.loaded
+	c.spaceUsers.mu.Unlock()
+	if loaded {
+		return nil
+	}
+
+	resp, err := c.GetSpaces(ctx)
+	if err != nil {
+		return fmt.Errorf("load spaces for user mapping: %w", err)
+	}
+	users := map[string][]string{}
+	emails := map[string]string{}
+	for _, userID := range SortedKeys(resp) {
+		tables, _ := resp[userID].(map[string]any)
+		flat := FlattenRecordMap(map[string]any{"recordMap": tables})
+		for spaceID := range flat["space"] {
+			users[spaceID] = append(users[spaceID], userID)
+		}
+		if email, _ := flat["notion_user"][userID]["email"].(string); email != "" {
+			emails[strings.ToLower(email)] = userID
+		}
+	}
+
+	c.spaceUsers.mu.Lock()
+	c.spaceUsers.users = users
+	c.spaceUsers.emails = emails
+	c.spaceUsers.loaded = true
+	c.spaceUsers.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,136 @@
+package notionclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type enqueueTaskRequest struct {
+	Task map[string]any `json:"task"`
+}
+
+type getTasksRequest struct {
+	TaskIDs []string `json:"taskIds"`
+}
+
+// ExportOptions mirrors the exportOptions object of an exportBlock task.
+type ExportOptions struct {
+	ExportType      string `json:"exportType"`
+	TimeZone        string `json:"timeZone"`
+	Locale          string `json:"locale"`
+	IncludeContents string `json:"includeContents,omitempty"`
+}
+
+// Task is the subset of a getTasks result nocli cares about.
+type Task struct {
+	ID            string
+	State         string
+	PagesExported int
+	ExportURL     string
+	Error         string
+	Raw           map[string]any
+}
+
+func (c *Client) EnqueueExportBlock(ctx context.Context, blockID string, spaceID string, recursive bool, opts ExportOptions) (string, error) {
+	resp, err := c.postJSON(ctx, "/api/v3/enqueueTask", enqueueTaskRequest{Task: map[string]any{
+		"eventName": "exportBlock",
+		"request": map[string]any{
+			"block":         map[string]any{"id": blockID, "spaceId": spaceID},
+			"recursive":     recursive,
+			"exportOptions": opts,
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+	taskID, _ := resp["taskId"].(string)
+	if taskID == "" {
+		return "", fmt.Errorf("enqueueTask response missing taskId")
+	}
+	return taskID, nil
+}
+
+func (c *Client) GetTask(ctx context.Context, taskID string) (Task, error) {
+	resp, err := c.postJSON(ctx, "/api/v3/getTasks", getTasksRequest{TaskIDs: []string{taskID}})
+	if err != nil {
+		return Task{}, err
+	}
+	results, _ := resp["results"].([]any)
+	for _, r := range results {
+		m, _ := r.(map[string]any)
+		if id, _ := m["id"].(string); id != taskID {
+			continue
+		}
+		task := Task{ID: taskID, Raw: m}
+		task.State, _ = m["state"].(string)
+		if status, ok := m["status"].(map[string]any); ok {
+			if n, err := parseInt64(status["pagesExported"]); err == nil {
+				task.PagesExported = int(n)
+			}
+			task.ExportURL, _ = status["exportURL"].(string)
+		}
+		switch e := m["error"].(type) {
+		case string:
+			task.Error = e
+		case map[string]any:
+			task.Error = fmt.Sprint(e["message"])
+		}
+		return task, nil
+	}
+	return Task{}, fmt.Errorf("task %s not found in getTasks response", taskID)
+}
+
+// Download GETs rawURL into w. Notion-hosted URLs (export archives, signed
+// files) receive the session cookie; third-party URLs do not.
+func (c *Client) Download(ctx context.Context, rawURL string, w io.Writer) (int64, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, fmt.Errorf("parse download URL: %w", err)
+	}
+	if !u.IsAbs() {
+		u = c.baseURL.ResolveReference(u)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("create download request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 notion-cli/0.1")
+	if c.isNotionHost(u.Hostname()) {
+		if cookie := c.cookieHeader(); cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+	}
+
+	hc := *c.httpClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("download %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return 0, fmt.Errorf("download failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("read download body: %w", err)
+	}
+	return n, nil
+}
+
+func (c *Client) isNotionHost(host string) bool {
+	host = strings.ToLower(host)
+	base := strings.ToLower(c.baseURL.Hostname())
+	if host == base {
+		return true
+	}
+	return host == "notion.so" || strings.HasSuffix(host, ".notion.so")
+}
@@ -0,0 +1,126 @@
+package notionclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Pointer addresses a single record in a transaction operation.
+type Pointer struct {
+	Table   string `json:"table"`
+	ID      string `json:"id"`
+	SpaceID string `json:"spaceId,omitempty"`
+}
+
+// Operation is one entry of a saveTransactions operation list.
+type Operation struct {
+	Pointer Pointer  `json:"pointer"`
+	Path    []string `json:"path"`
+	Command string   `json:"command"`
+	Args    any      `json:"args"`
+}
+
+type transaction struct {
+	ID         string         `json:"id"`
+	SpaceID    string         `json:"spaceId"`
+	Debug      map[string]any `json:"debug"`
+	Operations []Operation    `json:"operations"`
+}
+
+type saveTransactionsRequest struct {
+	RequestID    string        `json:"requestId"`
+	Transactions []transaction `json:"transactions"`
+}
+
+func SetOp(table string, id string, spaceID string, path []string, args any) Operation {
+	return Operation{Pointer: Pointer{Table: table, ID: id, SpaceID: spaceID}, Path: nonNilPath(path), Command: "set", Args: args}
+}
+
+func UpdateOp(table string, id string, spaceID string, path []string, args map[string]any) Operation {
+	return Operation{Pointer: Pointer{Table: table, ID: id, SpaceID: spaceID}, Path: nonNilPath(path), Command: "update", Args: args}
+}
+
+// ListAfterOp inserts itemID into the list at path, after afterID when set,
+// otherwise at the end.
+func ListAfterOp(table string, id string, spaceID string, path []string, itemID string, afterID string) Operation {
+	args := map[string]any{"id": itemID}
+	if afterID != "" {
+		args["after"] = afterID
+	}
+	return Operation{Pointer: Pointer{Table: table, ID: id, SpaceID: spaceID}, Path: nonNilPath(path), Command: "listAfter", Args: args}
+}
+
+func ListRemoveOp(table string, id string, spaceID string, path []string, itemID string) Operation {
+	return Operation{Pointer: Pointer{Table: table, ID: id, SpaceID: spaceID}, Path: nonNilPath(path), Command: "listRemove", Args: map[string]any{"id": itemID}}
+}
+
+func nonNilPath(path []string) []string {
+	if path == nil {
+		return []string{}
+	}
+	return path
+}
+
+// SaveTransactions submits ops as a single transaction in spaceID.
+func (c *Client) SaveTransactions(ctx context.Context, spaceID string, userAction string, ops []Operation) (map[string]any, error) {
+	if strings.TrimSpace(spaceID) == "" {
+		return nil, fmt.Errorf("save transactions: space id is empty")
+	}
+	if len(ops) == 0 {
+		return map[string]any{}, nil
+	}
+	requestID, err := NewID()
+	if err != nil {
+		return nil, err
+	}
+	transactionID, err := NewID()
+	if err != nil {
+		return nil, err
+	}
+	payload := saveTransactionsRequest{
+		RequestID: requestID,
+		Transactions: []transaction{{
+			ID:         transactionID,
+			SpaceID:    spaceID,
+			Debug:      map[string]any{"userAction": userAction},
+			Operations: ops,
+		}},
+	}
+	return c.ForSpace(ctx, spaceID).postJSON(ctx, "/api/v3/saveTransactionsFanout", payload)
+}
+
+// NewBlockRecord returns the minimal record value for a fresh block created by
+// the current user under parentID.
+func (c *Client) NewBlockRecord(id string, blockType string, parentID string, parentTable string, spaceID string, nowMillis int64) map[string]any {
+	rec := map[string]any{
+		"id":               id,
+		"type":             blockType,
+		"version":          1,
+		"alive":            true,
+		"parent_id":        parentID,
+		"parent_table":     parentTable,
+		"space_id":         spaceID,
+		"created_time":     nowMillis,
+		"last_edited_time": nowMillis,
+	}
+	if user := c.CurrentUserID(); user != "" {
+		rec["created_by_id"] = user
+		rec["created_by_table"] = "notion_user"
+		rec["last_edited_by_id"] = user
+		rec["last_edited_by_table"] = "notion_user"
+	}
+	return rec
+}
+
+// CurrentUserID is the user that edits are attributed to: the active user
+// header when set, otherwise the notion_user_id cookie.
+func (c *Client) CurrentUserID() string {
+	if c.activeUserID != "" {
+		return c.activeUserID
+	}
+	if c.notionUserID != "" {
+		return c.notionUserID
+	}
+	return ParseCookieValue(c.cookie, "notion_user_id")
+}
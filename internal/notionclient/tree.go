@@ -0,0 +1,352 @@
+package notionclient
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const syncBatchSize = 100
+
+// TreeOptions controls FetchTree.
+type TreeOptions struct {
+	// MaxDepth limits how many levels of subpages below the roots are
+	// expanded. Pages one level beyond the limit are still fetched, but not
+	// their content. Zero means unlimited.
+	MaxDepth int
+	// RootOnly expands only the roots: their subpages and database rows are
+	// fetched, but not their content. It overrides MaxDepth.
+	RootOnly bool
+	// IncludeCollections fetches collections and views of database blocks and
+	// queries their rows as child pages.
+	IncludeCollections bool
+	// RowLimit caps rows per collection query (default 1000).
+	RowLimit int
+	// Cached, when set, is consulted before fetching a record from Notion.
+	Cached func(table string, id string) (map[string]any, bool)
+	// OnRecord is called once for every record added to the tree.
+	OnRecord func(table string, id string, value map[string]any) error
+}
+
+// Tree is the result of FetchTree: the records reachable from the roots in
+// FlattenRecordMap's table -> id -> value shape.
+type Tree struct {
+	Roots   []string
+	Records map[string]map[string]map[string]any
+	// Depth is the page depth of each block relative to the roots.
+	Depth map[string]int
+	// Missing lists block IDs referenced from content that came back empty
+	// (deleted or not shared with the current user).
+	Missing []string
+}
+
+func (t *Tree) Block(id string) map[string]any {
+	return t.Records["block"][id]
+}
+
+// IsPageBlock reports whether a block type starts a new page.
+func IsPageBlock(block map[string]any) bool {
+	switch typ, _ := block["type"].(string); typ {
+	case "page", "collection_view_page":
+		return true
+	}
+	return false
+}
+
+type treeItem struct {
+	id string
+	// depth is the parent's page depth, or 0 for roots.
+	depth int
+	root  bool
+}
+
+// FetchTree walks block content recursively from rootIDs, batching
+// syncRecordValues calls per level.
+func (c *Client) FetchTree(ctx context.Context, rootIDs []string, opts TreeOptions) (*Tree, error) {
+	if opts.RowLimit <= 0 {
+		opts.RowLimit = 1000
+	}
+	t := &Tree{
+		Roots:   rootIDs,
+		Records: map[string]map[string]map[string]any{},
+		Depth:   map[string]int{},
+	}
+	queued := map[string]bool{}
+	prefetched := map[string]map[string]any{}
+
+	level := make([]treeItem, 0, len(rootIDs))
+	for _, id := range rootIDs {
+		if !queued[id] {
+			queued[id] = true
+			level = append(level, treeItem{id: id, root: true})
+		}
+	}
+
+	for len(level) > 0 {
+		blocks, err := c.resolveRecords(ctx, "block", itemIDs(level), prefetched, opts.Cached)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make([]treeItem, 0)
+		for _, item := range level {
+			block := blocks[item.id]
+			if len(block) == 0 {
+				t.Missing = append(t.Missing, item.id)
+				continue
+			}
+			depth := item.depth
+			if !item.root && IsPageBlock(block) {
+				depth++
+			}
+			if err := t.add("block", item.id, block, opts.OnRecord); err != nil {
+				return nil, err
+			}
+			t.Depth[item.id] = depth
+			if (opts.RootOnly && depth > 0) || (opts.MaxDepth > 0 && depth > opts.MaxDepth) {
+				continue
+			}
+
+			for _, childID := range ContentIDs(block) {
+				if !queued[childID] {
+					queued[childID] = true
+					next = append(next, treeItem{id: childID, depth: depth})
+				}
+			}
+
+			if opts.IncludeCollections && isCollectionBlock(block) {
+				rowIDs, err := c.fetchCollection(ctx, t, block, opts, prefetched)
+				if err != nil {
+					return nil, err
+				}
+				for _, rowID := range rowIDs {
+					if !queued[rowID] {
+						queued[rowID] = true
+						next = append(next, treeItem{id: rowID, depth: depth})
+					}
+				}
+			}
+		}
+		level = next
+	}
+
+	return t, nil
+}
+
+func (c *Client) fetchCollection(ctx context.Context, t *Tree, block map[string]any, opts TreeOptions, prefetched map[string]map[string]any) ([]string, error) {
+	viewIDs := StringList(block["view_ids"])
+	if len(viewIDs) > 0 {
+		views, err := c.resolveRecords(ctx, "collection_view", viewIDs, nil, opts.Cached)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range viewIDs {
+			if v := views[id]; len(v) > 0 {
+				if err := t.add("collection_view", id, v, opts.OnRecord); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	collectionID := CollectionIDForBlock(block, t.Records["collection_view"])
+	if collectionID == "" {
+		return nil, nil
+	}
+	cols, err := c.resolveRecords(ctx, "collection", []string{collectionID}, nil, opts.Cached)
+	if err != nil {
+		return nil, err
+	}
+	if col := cols[collectionID]; len(col) > 0 {
+		if err := t.add("collection", collectionID, col, opts.OnRecord); err != nil {
+			return nil, err
+		}
+	}
+	if len(viewIDs) == 0 {
+		return nil, nil
+	}
+
+	resp, err := c.QueryCollection(ctx, collectionID, viewIDs[0], opts.RowLimit)
+	if err != nil {
+		return nil, fmt.Errorf("query collection %s: %w", collectionID, err)
+	}
+	for id, row := range FlattenRecordMap(resp)["block"] {
+		prefetched[id] = row
+	}
+	return CollectionRowIDs(resp), nil
+}
+
+// CollectionIDForBlock returns the collection behind a database block, using
+// the view's collection_pointer for linked databases.
+func CollectionIDForBlock(block map[string]any, views map[string]map[string]any) string {
+	if id, _ := block["collection_id"].(string); id != "" {
+		return id
+	}
+	for _, viewID := range StringList(block["view_ids"]) {
+		format, _ := views[viewID]["format"].(map[string]any)
+		pointer, _ := format["collection_pointer"].(map[string]any)
+		if id, _ := pointer["id"].(string); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// RowsByCollection returns the live rows of each collection in records,
+// oldest first.
+func RowsByCollection(records map[string]map[string]map[string]any) map[string][]string {
+	blocks := records["block"]
+	out := map[string][]string{}
+	for _, id := range SortedKeys(blocks) {
+		block := blocks[id]
+		if pt, _ := block["parent_table"].(string); pt == "collection" && IsAlive(block) {
+			cid, _ := block["parent_id"].(string)
+			out[cid] = append(out[cid], id)
+		}
+	}
+	for _, ids := range out {
+		sort.SliceStable(ids, func(i, j int) bool {
+			a, _ := blocks[ids[i]]["created_time"].(float64)
+			b, _ := blocks[ids[j]]["created_time"].(float64)
+			return a < b
+		})
+	}
+	return out
+}
+
+// PageTitle returns the title of a page block; full-page databases are
+// titled by their collection's name when it has one.
+func PageTitle(records map[string]map[string]map[string]any, block map[string]any) string {
+	title := BlockTitle(block)
+	if block["type"] == "collection_view_page" {
+		if cid := CollectionIDForBlock(block, records["collection_view"]); cid != "" {
+			if name := PlainText(records["collection"][cid]["name"]); name != "" {
+				title = name
+			}
+		}
+	}
+	return title
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug turns a page title into a lower-case, dash-separated file name of at
+// most 60 characters; it is "" for titles without letters or digits.
+func Slug(title string) string {
+	s := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(s) > 60 {
+		s = strings.Trim(s[:60], "-")
+	}
+	return s
+}
+
+func (t *Tree) add(table string, id string, value map[string]any, onRecord func(string, string, map[string]any) error) error {
+	if t.Records[table] == nil {
+		t.Records[table] = map[string]map[string]any{}
+	}
+	if _, seen := t.Records[table][id]; seen {
+		return nil
+	}
+	t.Records[table][id] = value
+	if onRecord != nil {
+		return onRecord(table, id, value)
+	}
+	return nil
+}
+
+// resolveRecords returns records for ids, preferring prefetched and cached
+// values and fetching the rest in batches.
+func (c *Client) resolveRecords(ctx context.Context, table string, ids []string, prefetched map[string]map[string]any, cached func(string, string) (map[string]any, bool)) (map[string]map[string]any, error) {
+	out := make(map[string]map[string]any, len(ids))
+	missing := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if v, ok := prefetched[id]; ok && len(v) > 0 {
+			out[id] = v
+			continue
+		}
+		if cached != nil {
+			if v, ok := cached(table, id); ok {
+				out[id] = v
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+
+	for start := 0; start < len(missing); start += syncBatchSize {
+		end := min(start+syncBatchSize, len(missing))
+		resp, err := c.SyncRecords(ctx, table, missing[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s records: %w", table, err)
+		}
+		for id, v := range FlattenRecordMap(resp)[table] {
+			out[id] = v
+		}
+	}
+	return out, nil
+}
+
+// GetRecords fetches records of one table in batches and returns them keyed
+// by ID. Records the user cannot read are absent from the result.
+func (c *Client) GetRecords(ctx context.Context, table string, ids []string) (map[string]map[string]any, error) {
+	return c.resolveRecords(ctx, table, ids, nil, nil)
+}
+
+// ChangedRecords re-syncs records at known versions in batches and returns
+// only those whose version moved. Records that became unreadable come back
+// without an "id".
+func (c *Client) ChangedRecords(ctx context.Context, table string, versions map[string]int64) (map[string]map[string]any, error) {
+	ids := SortedKeys(versions)
+	out := map[string]map[string]any{}
+	for start := 0; start < len(ids); start += syncBatchSize {
+		end := min(start+syncBatchSize, len(ids))
+		resp, err := c.SyncRecordVersions(ctx, table, ids[start:end], versions)
+		if err != nil {
+			return nil, fmt.Errorf("sync %s records: %w", table, err)
+		}
+		for id, v := range FlattenRecordMap(resp)[table] {
+			known, ok := versions[id]
+			if version, _ := v["version"].(float64); ok && int64(version) != known {
+				out[id] = v
+			}
+		}
+	}
+	return out, nil
+}
+
+// ContentIDs returns the child block IDs listed in a block's content.
+func ContentIDs(block map[string]any) []string {
+	return StringList(block["content"])
+}
+
+func isCollectionBlock(block map[string]any) bool {
+	switch typ, _ := block["type"].(string); typ {
+	case "collection_view", "collection_view_page":
+		return true
+	}
+	return false
+}
+
+func itemIDs(items []treeItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.id)
+	}
+	return ids
+}
+
+// StringList returns the non-empty strings of a JSON array such as a
+// block's content or view_ids.
+func StringList(v any) []string {
+	arr, _ := v.([]any)
+	out := make([]string, 0, len(arr))
+	for _, x := range arr {
+		s, _ := x.(string)
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
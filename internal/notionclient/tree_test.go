@@ -0,0 +1,56 @@
+package notionclient
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRowsByCollection(t *testing.T) {
+	records := map[string]map[string]map[string]any{"block": {
+		"b": {"parent_table": "collection", "parent_id": "c", "created_time": 1.0},
+		"a": {"parent_table": "collection", "parent_id": "c", "created_time": 2.0},
+		"d": {"parent_table": "collection", "parent_id": "c", "created_time": 1.0},
+		"x": {"parent_table": "collection", "parent_id": "c", "alive": false},
+		"p": {"parent_table": "block", "parent_id": "c"},
+	}}
+	want := map[string][]string{"c": {"b", "d", "a"}}
+	if got := RowsByCollection(records); !reflect.DeepEqual(got, want) {
+		t.Errorf("RowsByCollection = %v, want %v", got, want)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  --Q3 Plan--  ", "q3-plan"},
+		{"Über", "ber"},
+		{"日本語", ""},
+		{strings.Repeat("ab ", 30), strings.TrimSuffix(strings.Repeat("ab-", 20), "-")},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.in); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPageTitle(t *testing.T) {
+	records := map[string]map[string]map[string]any{
+		"collection": {"c": {"name": []any{[]any{"Tasks"}}}},
+	}
+	page := map[string]any{"type": "page", "properties": map[string]any{"title": []any{[]any{"Notes"}}}}
+	db := map[string]any{"type": "collection_view_page", "collection_id": "c"}
+	unnamed := map[string]any{"type": "collection_view_page", "collection_id": "missing", "properties": map[string]any{"title": []any{[]any{"Old"}}}}
+	for _, tt := range []struct {
+		block map[string]any
+		want  string
+	}{{page, "Notes"}, {db, "Tasks"}, {unnamed, "Old"}} {
+		if got := PageTitle(records, tt.block); got != tt.want {
+			t.Errorf("PageTitle(%v) = %q, want %q", tt.block["type"], got, tt.want)
+		}
+	}
+}
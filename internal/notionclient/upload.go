@@ -0,0 +1,83 @@
+package notionclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type getUploadFileURLRequest struct {
+	Bucket        string `json:"bucket"`
+	Name          string `json:"name"`
+	ContentType   string `json:"contentType"`
+	ContentLength int64  `json:"contentLength,omitempty"`
+}
+
+// UploadTarget is the result of the getUploadFileUrl handshake.
+type UploadTarget struct {
+	URL          string
+	SignedGetURL string
+	SignedPutURL string
+	PutHeaders   map[string]string
+}
+
+func (c *Client) GetUploadFileURL(ctx context.Context, name string, contentType string, size int64) (UploadTarget, error) {
+	resp, err := c.postJSON(ctx, "/api/v3/getUploadFileUrl", getUploadFileURLRequest{
+		Bucket:        "secure",
+		Name:          name,
+		ContentType:   contentType,
+		ContentLength: size,
+	})
+	if err != nil {
+		return UploadTarget{}, err
+	}
+
+	target := UploadTarget{PutHeaders: map[string]string{}}
+	target.URL, _ = resp["url"].(string)
+	target.SignedGetURL, _ = resp["signedGetUrl"].(string)
+	target.SignedPutURL, _ = resp["signedPutUrl"].(string)
+	if headers, ok := resp["putHeaders"].([]any); ok {
+		for _, h := range headers {
+			m, _ := h.(map[string]any)
+			name, _ := m["name"].(string)
+			value, _ := m["value"].(string)
+			if name != "" {
+				target.PutHeaders[name] = value
+			}
+		}
+	}
+	if target.URL == "" || target.SignedPutURL == "" {
+		return UploadTarget{}, fmt.Errorf("getUploadFileUrl response missing url/signedPutUrl")
+	}
+	return target, nil
+}
+
+// PutUpload streams body to the signed PUT URL returned by GetUploadFileURL.
+func (c *Client) PutUpload(ctx context.Context, target UploadTarget, contentType string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.SignedPutURL, body)
+	if err != nil {
+		return fmt.Errorf("create upload request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range target.PutHeaders {
+		req.Header.Set(k, v)
+	}
+
+	// Uploads can take much longer than API calls; rely on ctx for cancellation.
+	hc := *c.httpClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("upload failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package notionclient
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// User is the public profile of a notion_user record.
+type User struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+}
+
+// UserResolver looks up notion_user records in batches and caches them,
+// including IDs that could not be resolved.
+type UserResolver struct {
+	client *Client
+
+	mu    sync.Mutex
+	users map[string]*User
+}
+
+func NewUserResolver(client *Client) *UserResolver {
+	return &UserResolver{client: client, users: map[string]*User{}}
+}
+
+// Resolve returns the users among ids that Notion knows about.
+func (r *UserResolver) Resolve(ctx context.Context, ids []string) (map[string]User, error) {
+	r.mu.Lock()
+	missing := make([]string, 0)
+	for _, id := range ids {
+		if _, ok := r.users[id]; !ok {
+			missing = append(missing, id)
+			r.users[id] = nil
+		}
+	}
+	r.mu.Unlock()
+
+	for start := 0; start < len(missing); start += syncBatchSize {
+		end := min(start+syncBatchSize, len(missing))
+		resp, err := r.client.GetUsers(ctx, missing[start:end])
+		if err != nil {
+			r.forget(missing[start:])
+			return nil, fmt.Errorf("fetch users: %w", err)
+		}
+		results, _ := resp["results"].([]any)
+		r.mu.Lock()
+		for _, res := range results {
+			m, _ := res.(map[string]any)
+			value, _ := m["value"].(map[string]any)
+			if u := userFromRecord(value); u != nil {
+				r.users[u.ID] = u
+			}
+		}
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]User, len(ids))
+	for _, id := range ids {
+		if u := r.users[id]; u != nil {
+			out[id] = *u
+		}
+	}
+	return out, nil
+}
+
+func (r *UserResolver) forget(ids []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		delete(r.users, id)
+	}
+}
+
+func userFromRecord(value map[string]any) *User {
+	id, _ := value["id"].(string)
+	if id == "" {
+		return nil
+	}
+	name, _ := value["name"].(string)
+	if name == "" {
+		given, _ := value["given_name"].(string)
+		family, _ := value["family_name"].(string)
+		name = strings.TrimSpace(given + " " + family)
+	}
+	email, _ := value["email"].(string)
+	avatar, _ := value["profile_photo"].(string)
+	return &User{ID: id, Name: name, Email: email, AvatarURL: avatar}
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// CollectUserIDs walks decoded JSON and returns the user IDs it references:
+// *_by_id and user_id fields, partial user objects, and user mention or
+// people-property tokens (["u", id]).
+func CollectUserIDs(v any) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0)
+	add := func(id string) {
+		if uuidPattern.MatchString(id) && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+
+	var walk func(any)
+	walk = func(v any) {
+		switch x := v.(type) {
+		case map[string]any:
+			if obj, _ := x["object"].(string); obj == "user" {
+				id, _ := x["id"].(string)
+				add(id)
+			}
+			for _, k := range SortedKeys(x) {
+				if s, ok := x[k].(string); ok && (strings.HasSuffix(k, "_by_id") || k == "user_id") {
+					add(s)
+					continue
+				}
+				walk(x[k])
+			}
+		case []any:
+			if len(x) == 2 {
+				if tag, _ := x[0].(string); tag == "u" {
+					id, _ := x[1].(string)
+					add(id)
+					return
+				}
+			}
+			for _, item := range x {
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+	return out
+}
+
+// FillUserObjects adds name, email and avatar_url to every partial user
+// object ({"object": "user", "id": ...}) in decoded JSON.
+func FillUserObjects(v any, users map[string]User) {
+	switch x := v.(type) {
+	case map[string]any:
+		if obj, _ := x["object"].(string); obj == "user" {
+			id, _ := x["id"].(string)
+			if u, ok := users[id]; ok {
+				setIfNotEmpty(x, "name", u.Name)
+				setIfNotEmpty(x, "email", u.Email)
+				setIfNotEmpty(x, "avatar_url", u.AvatarURL)
+			}
+		}
+		for _, item := range x {
+			FillUserObjects(item, users)
+		}
+	case []any:
+		for _, item := range x {
+			FillUserObjects(item, users)
+		}
+	}
+}
+
+func setIfNotEmpty(m map[string]any, key string, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
+
+// FindUser looks up a user by email address, including users that are not
+// logged in with the current cookie.
+func (c *Client) FindUser(ctx context.Context, email string) (string, error) {
+	resp, err := c.postJSON(ctx, "/api/v3/findUser", map[string]any{"email": email})
+	if err != nil {
+		return "", err
+	}
+	value, _ := unwrapRecordValue(resp["value"])
+	if id, _ := value["id"].(string); id != "" {
+		return id, nil
+	}
+	return "", fmt.Errorf("no Notion user with email %s", email)
+}
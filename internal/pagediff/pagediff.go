@@ -0,0 +1,313 @@
+// Package pagediff compares two states of a page's block tree by block ID and
+// reports inserted, removed, moved and edited blocks.
+package pagediff
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+// State is one version of a page in FlattenRecordMap's table -> id -> value
+// shape.
+type State struct {
+	Label   string
+	Records map[string]map[string]map[string]any
+}
+
+// Change kinds.
+const (
+	Inserted = "inserted"
+	Removed  = "removed"
+	Moved    = "moved"
+	Edited   = "edited"
+)
+
+// Change describes what happened to one block between the two states.
+type Change struct {
+	Kind  string `json:"kind"`
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+	// Row is set for database rows, whose property changes are reported by
+	// schema name.
+	Row bool `json:"row,omitempty"`
+
+	FromParent string `json:"from_parent,omitempty"`
+	ToParent   string `json:"to_parent,omitempty"`
+	FromIndex  int    `json:"from_index"`
+	ToIndex    int    `json:"to_index"`
+
+	FromType   string           `json:"from_type,omitempty"`
+	Properties []PropertyChange `json:"properties,omitempty"`
+	// FormatChanged reports layout/format differences without details.
+	FormatChanged bool `json:"format_changed,omitempty"`
+}
+
+// PropertyChange is a changed entry of a block's properties.
+type PropertyChange struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// WordDiff marks removed words as [-x-] and added words as {+x+}.
+	WordDiff string `json:"word_diff,omitempty"`
+	// Decorations is true when only bold/links/mentions etc. changed.
+	Decorations bool `json:"decorations_only,omitempty"`
+}
+
+// Result is the outcome of Diff.
+type Result struct {
+	PageID  string         `json:"page_id"`
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Changes []Change       `json:"changes"`
+	Summary map[string]int `json:"summary"`
+}
+
+type node struct {
+	block  map[string]any
+	parent string
+	index  int
+	row    bool
+}
+
+// Diff compares the block trees rooted at pageID in from and to.
+func Diff(pageID string, from State, to State) Result {
+	a := walk(pageID, from.Records)
+	b := walk(pageID, to.Records)
+	res := Result{
+		PageID:  pageID,
+		From:    from.Label,
+		To:      to.Label,
+		Changes: []Change{},
+		Summary: map[string]int{Inserted: 0, Removed: 0, Moved: 0, Edited: 0},
+	}
+	moved := movedBlocks(a.order, b.order, a.nodes, b.nodes)
+
+	if na, ok := a.nodes[pageID]; ok {
+		if nb, ok := b.nodes[pageID]; ok {
+			if c, ok := editChange(pageID, na, nb, from.Records, to.Records); ok {
+				res.add(c)
+			}
+		}
+	}
+
+	for _, id := range b.order {
+		nb := b.nodes[id]
+		na, ok := a.nodes[id]
+		if !ok {
+			res.add(newChange(Inserted, id, nb, nb))
+			continue
+		}
+		if moved[id] {
+			res.add(newChange(Moved, id, na, nb))
+		}
+		if c, ok := editChange(id, na, nb, from.Records, to.Records); ok {
+			res.add(c)
+		}
+	}
+	for _, id := range a.order {
+		if _, ok := b.nodes[id]; !ok {
+			na := a.nodes[id]
+			res.add(newChange(Removed, id, na, na))
+		}
+	}
+	return res
+}
+
+func (r *Result) add(c Change) {
+	r.Changes = append(r.Changes, c)
+	r.Summary[c.Kind]++
+}
+
+func newChange(kind string, id string, from node, to node) Change {
+	typ, _ := to.block["type"].(string)
+	c := Change{
+		Kind:      kind,
+		ID:        id,
+		Type:      typ,
+		Title:     notionclient.BlockTitle(to.block),
+		Row:       to.row,
+		FromIndex: -1,
+		ToIndex:   -1,
+	}
+	if kind != Inserted {
+		c.FromParent, c.FromIndex = from.parent, from.index
+	}
+	if kind != Removed {
+		c.ToParent, c.ToIndex = to.parent, to.index
+	}
+	return c
+}
+
+type tree struct {
+	nodes map[string]node
+	order []string
+	// children lists child IDs per parent in order.
+	children map[string][]string
+}
+
+// walk collects the live blocks of a page in document order: content of the
+// root and nested blocks, plus rows of databases on the page. Subpages are
+// included but not descended into. The root is in nodes but not in order.
+func walk(pageID string, records map[string]map[string]map[string]any) tree {
+	t := tree{nodes: map[string]node{}, children: map[string][]string{}}
+	blocks := records["block"]
+
+	rowsByCollection := notionclient.RowsByCollection(records)
+
+	var visit func(id string, parent string, row bool)
+	visit = func(id string, parent string, row bool) {
+		b := blocks[id]
+		if b == nil || !notionclient.IsAlive(b) {
+			return
+		}
+		if _, seen := t.nodes[id]; seen {
+			return
+		}
+		if id == pageID {
+			// The page itself is compared for edits, but has no position.
+			pt, _ := b["parent_table"].(string)
+			pid, _ := b["parent_id"].(string)
+			t.nodes[id] = node{block: b, parent: pid, index: -1, row: pt == "collection"}
+		} else {
+			t.nodes[id] = node{block: b, parent: parent, index: len(t.children[parent]), row: row}
+			t.children[parent] = append(t.children[parent], id)
+			t.order = append(t.order, id)
+			if notionclient.IsPageBlock(b) {
+				return
+			}
+		}
+		for _, child := range notionclient.ContentIDs(b) {
+			visit(child, id, false)
+		}
+		if cid := notionclient.CollectionIDForBlock(b, records["collection_view"]); cid != "" {
+			for _, rowID := range rowsByCollection[cid] {
+				visit(rowID, cid, true)
+			}
+		}
+	}
+	visit(pageID, "", false)
+	return t
+}
+
+// movedBlocks reports blocks whose parent changed, or whose order relative
+// to the siblings present in both states changed (outside the longest
+// common subsequence of those siblings).
+func movedBlocks(fromOrder []string, toOrder []string, from map[string]node, to map[string]node) map[string]bool {
+	moved := map[string]bool{}
+	for _, id := range toOrder {
+		if a, ok := from[id]; ok && a.parent != to[id].parent {
+			moved[id] = true
+		}
+	}
+
+	siblings := func(order []string, nodes map[string]node, other map[string]node) map[string][]string {
+		out := map[string][]string{}
+		for _, id := range order {
+			n := nodes[id]
+			if o, ok := other[id]; ok && o.parent == n.parent {
+				out[n.parent] = append(out[n.parent], id)
+			}
+		}
+		return out
+	}
+	a := siblings(fromOrder, from, to)
+	b := siblings(toOrder, to, from)
+	for parent, ids := range b {
+		keep := map[string]bool{}
+		for _, id := range lcs(a[parent], ids) {
+			keep[id] = true
+		}
+		for _, id := range ids {
+			if !keep[id] {
+				moved[id] = true
+			}
+		}
+	}
+	return moved
+}
+
+func editChange(id string, from node, to node, fromRecords map[string]map[string]map[string]any, toRecords map[string]map[string]map[string]any) (Change, bool) {
+	c := newChange(Edited, id, from, to)
+	changed := false
+
+	fromType, _ := from.block["type"].(string)
+	if fromType != c.Type {
+		c.FromType = fromType
+		changed = true
+	}
+
+	schema := rowSchema(to, toRecords)
+	if len(schema) == 0 {
+		schema = rowSchema(from, fromRecords)
+	}
+	fp, _ := from.block["properties"].(map[string]any)
+	tp, _ := to.block["properties"].(map[string]any)
+	for _, key := range unionKeys(fp, tp) {
+		if reflect.DeepEqual(normalize(fp[key]), normalize(tp[key])) {
+			continue
+		}
+		pc := PropertyChange{
+			Key:    key,
+			Name:   propertyName(key, schema),
+			Before: notionclient.PlainText(fp[key]),
+			After:  notionclient.PlainText(tp[key]),
+		}
+		if pc.Before == pc.After {
+			pc.Decorations = true
+		} else {
+			pc.WordDiff = wordDiff(pc.Before, pc.After)
+		}
+		c.Properties = append(c.Properties, pc)
+		changed = true
+	}
+
+	if !reflect.DeepEqual(normalize(from.block["format"]), normalize(to.block["format"])) {
+		c.FormatChanged = true
+		changed = true
+	}
+	return c, changed
+}
+
+func rowSchema(n node, records map[string]map[string]map[string]any) map[string]any {
+	if !n.row {
+		return nil
+	}
+	schema, _ := records["collection"][n.parent]["schema"].(map[string]any)
+	return schema
+}
+
+func propertyName(key string, schema map[string]any) string {
+	if s, ok := schema[key].(map[string]any); ok {
+		if name, _ := s["name"].(string); name != "" {
+			return name
+		}
+	}
+	return key
+}
+
+func unionKeys(a map[string]any, b map[string]any) []string {
+	all := map[string]any{}
+	for k := range a {
+		all[k] = nil
+	}
+	for k := range b {
+		all[k] = nil
+	}
+	return notionclient.SortedKeys(all)
+}
+
+// normalize round-trips v through JSON so numbers compare equal regardless
+// of how the state was loaded.
+func normalize(v any) any {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	_ = json.Unmarshal(body, &out)
+	return out
+}
@@ -0,0 +1,255 @@
+package pagediff
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const pageID = "page"
+
+func block(typ string, title string, content ...string) map[string]any {
+	b := map[string]any{"type": typ, "alive": true}
+	if title != "" {
+		b["properties"] = map[string]any{"title": []any{[]any{title}}}
+	}
+	if len(content) > 0 {
+		ids := make([]any, 0, len(content))
+		for _, id := range content {
+			ids = append(ids, id)
+		}
+		b["content"] = ids
+	}
+	return b
+}
+
+func state(label string, blocks map[string]map[string]any) State {
+	return State{Label: label, Records: map[string]map[string]map[string]any{"block": blocks}}
+}
+
+// kinds returns "kind id" for every change, in order.
+func kinds(r Result) []string {
+	out := make([]string, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		out = append(out, c.Kind+" "+c.ID)
+	}
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	base := func() map[string]map[string]any {
+		return map[string]map[string]any{
+			pageID: block("page", "Page", "a", "b", "c"),
+			"a":    block("text", "alpha"),
+			"b":    block("toggle", "beta", "b1"),
+			"b1":   block("text", "inside"),
+			"c":    block("text", "gamma"),
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(m map[string]map[string]any)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			change: func(m map[string]map[string]any) {},
+			want:   []string{},
+		},
+		{
+			name: "inserted",
+			change: func(m map[string]map[string]any) {
+				m[pageID] = block("page", "Page", "a", "n", "b", "c")
+				m["n"] = block("text", "new")
+			},
+			want: []string{"inserted n"},
+		},
+		{
+			name: "removed",
+			change: func(m map[string]map[string]any) {
+				m[pageID] = block("page", "Page", "a", "b")
+			},
+			want: []string{"removed c"},
+		},
+		{
+			name: "archived counts as removed",
+			change: func(m map[string]map[string]any) {
+				m["a"]["alive"] = false
+			},
+			want: []string{"removed a"},
+		},
+		{
+			name: "reordered",
+			change: func(m map[string]map[string]any) {
+				m[pageID] = block("page", "Page", "c", "a", "b")
+			},
+			want: []string{"moved c"},
+		},
+		{
+			name: "moved to another parent",
+			change: func(m map[string]map[string]any) {
+				m[pageID] = block("page", "Page", "b", "c")
+				m["b"] = block("toggle", "beta", "b1", "a")
+			},
+			want: []string{"moved a"},
+		},
+		{
+			name: "edited",
+			change: func(m map[string]map[string]any) {
+				m["b1"] = block("text", "inside out")
+			},
+			want: []string{"edited b1"},
+		},
+		{
+			name: "page renamed",
+			change: func(m map[string]map[string]any) {
+				m[pageID] = block("page", "Renamed", "a", "b", "c")
+			},
+			want: []string{"edited page"},
+		},
+		{
+			name: "type changed",
+			change: func(m map[string]map[string]any) {
+				m["c"] = block("header", "gamma")
+			},
+			want: []string{"edited c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := base()
+			tt.change(to)
+			got := kinds(Diff(pageID, state("a", base()), state("b", to)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffDoesNotDescendIntoSubpages(t *testing.T) {
+	from := map[string]map[string]any{
+		pageID: block("page", "Page", "sub"),
+		"sub":  block("page", "Sub", "s1"),
+		"s1":   block("text", "before"),
+	}
+	to := map[string]map[string]any{
+		pageID: block("page", "Page", "sub"),
+		"sub":  block("page", "Sub", "s1"),
+		"s1":   block("text", "after"),
+	}
+	if r := Diff(pageID, state("a", from), state("b", to)); len(r.Changes) != 0 {
+		t.Errorf("changes = %q, want none", kinds(r))
+	}
+}
+
+func TestDiffProperties(t *testing.T) {
+	row := func(status string) map[string]any {
+		b := block("page", "Row")
+		b["parent_table"] = "collection"
+		b["parent_id"] = "coll"
+		b["properties"].(map[string]any)["st"] = []any{[]any{status}}
+		return b
+	}
+	records := func(text []any, status string) map[string]map[string]map[string]any {
+		db := block("collection_view", "")
+		db["collection_id"] = "coll"
+		return map[string]map[string]map[string]any{
+			"block": {
+				pageID: block("page", "Page", "t", "db"),
+				"t":    {"type": "text", "properties": map[string]any{"title": text}},
+				"db":   db,
+				"row":  row(status),
+			},
+			"collection": {
+				"coll": {"schema": map[string]any{"st": map[string]any{"name": "Status", "type": "select"}}},
+			},
+		}
+	}
+	from := State{Label: "a", Records: records([]any{[]any{"hello world"}}, "Open")}
+	to := State{Label: "b", Records: records([]any{[]any{"hello there"}}, "Done")}
+	r := Diff(pageID, from, to)
+	if got, want := kinds(r), []string{"edited t", "edited row"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("changes = %q, want %q", got, want)
+	}
+	text := r.Changes[0].Properties[0]
+	if text.WordDiff != "hello [-world-]{+there+}" {
+		t.Errorf("word diff = %q", text.WordDiff)
+	}
+	rowChange := r.Changes[1]
+	if !rowChange.Row || len(rowChange.Properties) != 1 || rowChange.Properties[0].Name != "Status" {
+		t.Errorf("row change = %+v, want one Status property change", rowChange)
+	}
+
+	bold := State{Label: "b", Records: records([]any{[]any{"hello world", []any{[]any{"b"}}}}, "Open")}
+	r = Diff(pageID, from, bold)
+	if len(r.Changes) != 1 || !r.Changes[0].Properties[0].Decorations {
+		t.Errorf("changes = %+v, want one decorations-only change", r.Changes)
+	}
+}
+
+func TestDiffRootRow(t *testing.T) {
+	records := func(status string) map[string]map[string]map[string]any {
+		page := block("page", "Row")
+		page["parent_table"] = "collection"
+		page["parent_id"] = "coll"
+		page["properties"].(map[string]any)["st"] = []any{[]any{status}}
+		return map[string]map[string]map[string]any{
+			"block":      {pageID: page},
+			"collection": {"coll": {"schema": map[string]any{"st": map[string]any{"name": "Status", "type": "select"}}}},
+		}
+	}
+	r := Diff(pageID, State{Label: "a", Records: records("Open")}, State{Label: "b", Records: records("Done")})
+	if got, want := kinds(r), []string{"edited page"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("changes = %q, want %q", got, want)
+	}
+	c := r.Changes[0]
+	if !c.Row || len(c.Properties) != 1 || c.Properties[0].Name != "Status" || c.Properties[0].After != "Done" {
+		t.Errorf("change = %+v, want the Status property of the row", c)
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	tests := []struct {
+		before, after, want string
+	}{
+		{"a b c", "a b c", "a b c"},
+		{"a b c", "a x c", "a [-b-]{+x+} c"},
+		{"a b", "a b c", "a b{+ c+}"},
+		{"one", "two", ""},
+	}
+	for _, tt := range tests {
+		if got := wordDiff(tt.before, tt.after); got != tt.want {
+			t.Errorf("wordDiff(%q, %q) = %q, want %q", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestLCS(t *testing.T) {
+	got := lcs(strings.Split("abcbdab", ""), strings.Split("bdcaba", ""))
+	if len(got) != 4 {
+		t.Errorf("lcs length = %d (%q), want 4", len(got), got)
+	}
+	if got := lcs(nil, []string{"a"}); got != nil {
+		t.Errorf("lcs(nil, a) = %q, want nil", got)
+	}
+}
+
+func TestWriteUnified(t *testing.T) {
+	r := Diff(pageID,
+		state("v1", map[string]map[string]any{pageID: block("page", "Page", "a"), "a": block("text", "old")}),
+		state("live", map[string]map[string]any{pageID: block("page", "Page", "a", "b"), "a": block("text", "new"), "b": block("text", "added")}),
+	)
+	var buf bytes.Buffer
+	if err := WriteUnified(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	want := "--- v1\n+++ live\n" +
+		"@@ edited text a @@\n-old\n+new\n" +
+		"@@ inserted text b (in page at 1) @@\n+added\n" +
+		"# 1 inserted, 0 removed, 0 moved, 1 edited\n"
+	if buf.String() != want {
+		t.Errorf("WriteUnified =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,166 @@
+package pagediff
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// maxLCSCells bounds the LCS table; larger inputs are treated as replaced
+// wholesale.
+const maxLCSCells = 4_000_000
+
+// lcs returns the longest common subsequence of a and b.
+func lcs(a []string, b []string) []string {
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxLCSCells {
+		return nil
+	}
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	out := make([]string, 0, dp[0][0])
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case dp[i+1][j] >= dp[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+var wordPattern = regexp.MustCompile(`\s+|[^\s]+`)
+
+// wordDiff renders the difference between two texts in git's --word-diff
+// style: [-removed-] and {+added+}. It returns "" when the texts share no
+// words, since the -/+ lines already say everything.
+func wordDiff(before string, after string) string {
+	a := wordPattern.FindAllString(before, -1)
+	b := wordPattern.FindAllString(after, -1)
+	common := lcs(a, b)
+	if len(common) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	var del, ins strings.Builder
+	flush := func() {
+		out.WriteString(wrap("[-", del.String(), "-]"))
+		out.WriteString(wrap("{+", ins.String(), "+}"))
+		del.Reset()
+		ins.Reset()
+	}
+	i, j := 0, 0
+	for _, tok := range common {
+		for ; a[i] != tok; i++ {
+			del.WriteString(a[i])
+		}
+		for ; b[j] != tok; j++ {
+			ins.WriteString(b[j])
+		}
+		flush()
+		out.WriteString(tok)
+		i++
+		j++
+	}
+	for ; i < len(a); i++ {
+		del.WriteString(a[i])
+	}
+	for ; j < len(b); j++ {
+		ins.WriteString(b[j])
+	}
+	flush()
+	return out.String()
+}
+
+func wrap(open string, s string, end string) string {
+	if s == "" {
+		return ""
+	}
+	return open + s + end
+}
+
+// WriteUnified renders r as unified-diff-like text: one hunk per change with
+// -/+ lines for text and property values.
+func WriteUnified(w io.Writer, r Result) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", r.From, r.To)
+	for _, c := range r.Changes {
+		fmt.Fprintf(&b, "@@ %s %s %s%s @@\n", c.Kind, c.Type, c.ID, hunkDetail(c))
+		switch c.Kind {
+		case Inserted:
+			writeLines(&b, "+", c.Title)
+		case Removed:
+			writeLines(&b, "-", c.Title)
+		case Edited:
+			if c.FromType != "" {
+				fmt.Fprintf(&b, "-type: %s\n+type: %s\n", c.FromType, c.Type)
+			}
+			for _, p := range c.Properties {
+				label := p.Name + ": "
+				if p.Key == "title" && !c.Row {
+					label = ""
+				}
+				if p.Decorations {
+					fmt.Fprintf(&b, " %s%s\n ~ formatting, links or mentions changed\n", label, p.Before)
+					continue
+				}
+				writeLines(&b, "-"+label, p.Before)
+				writeLines(&b, "+"+label, p.After)
+				if p.WordDiff != "" {
+					fmt.Fprintf(&b, " ~ %s\n", p.WordDiff)
+				}
+			}
+			if c.FormatChanged {
+				b.WriteString(" ~ format changed\n")
+			}
+		}
+	}
+	fmt.Fprintf(&b, "# %d inserted, %d removed, %d moved, %d edited\n", r.Summary[Inserted], r.Summary[Removed], r.Summary[Moved], r.Summary[Edited])
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func hunkDetail(c Change) string {
+	switch c.Kind {
+	case Inserted:
+		return fmt.Sprintf(" (in %s at %d)", short(c.ToParent), c.ToIndex)
+	case Removed:
+		return fmt.Sprintf(" (from %s at %d)", short(c.FromParent), c.FromIndex)
+	case Moved:
+		return fmt.Sprintf(" (%s#%d -> %s#%d)", short(c.FromParent), c.FromIndex, short(c.ToParent), c.ToIndex)
+	}
+	if c.Row {
+		return " (database row)"
+	}
+	return ""
+}
+
+func short(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func writeLines(b *strings.Builder, prefix string, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(b, "%s%s\n", prefix, line)
+	}
+}
@@ -0,0 +1,285 @@
+// Package pagegraph builds a graph of pages from a fetched block tree, with
+// typed edges for subpages, link-to-page blocks, inline mentions and
+// relation properties.
+package pagegraph
+
+import (
+	"sort"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+// Edge types.
+const (
+	Child    = "child"
+	Link     = "link"
+	Mention  = "mention"
+	Relation = "relation"
+)
+
+// Node kinds.
+const (
+	KindPage     = "page"
+	KindDatabase = "database"
+	KindRow      = "row"
+	// KindExternal is a page referenced from the crawled pages but outside
+	// of them (deeper than the depth limit, elsewhere in the workspace, or not
+	// accessible).
+	KindExternal = "external"
+)
+
+// Node is a page.
+type Node struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+	URL   string `json:"url,omitempty"`
+	// Depth is the subpage depth below the root, or -1 for external pages.
+	Depth int `json:"depth"`
+	// Inbound counts link, mention and relation edges pointing at the page.
+	Inbound int `json:"inbound"`
+	// Cluster numbers the groups of pages connected by link, mention and
+	// relation edges (ignoring the page hierarchy), largest first.
+	Cluster int `json:"cluster"`
+}
+
+// Edge is a typed reference from one page to another. Repeated references
+// of the same type are merged and counted.
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// Graph is the result of Build.
+type Graph struct {
+	Root  string `json:"root"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+type builder struct {
+	records map[string]map[string]map[string]any
+	nodes   map[string]*Node
+	order   []string
+	edges   map[[3]string]*Edge
+	edgeSeq [][3]string
+}
+
+// Build turns the records of a FetchTree result into a page graph. Edges
+// into pages that are not in the tree add external nodes; their titles are
+// left empty for the caller to fill in.
+func Build(tree *notionclient.Tree) *Graph {
+	b := &builder{
+		records: tree.Records,
+		nodes:   map[string]*Node{},
+		edges:   map[[3]string]*Edge{},
+	}
+	walk(tree, func(parent string, owner string, id string, block map[string]any, row bool) {
+		if owner == id {
+			b.addPage(id, block, tree.Depth[id], row)
+			if parent != "" {
+				b.addEdge(parent, id, Child)
+			}
+		}
+		for _, ref := range BlockReferences(tree.Records, block) {
+			b.addEdge(owner, ref.Target, ref.Type)
+		}
+	})
+
+	g := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	if len(tree.Roots) > 0 {
+		g.Root = tree.Roots[0]
+	}
+	for _, key := range b.edgeSeq {
+		e := b.edges[key]
+		if _, ok := b.nodes[e.To]; !ok {
+			b.nodes[e.To] = &Node{ID: e.To, Kind: KindExternal, Depth: -1}
+			b.order = append(b.order, e.To)
+		}
+		if e.Type != Child {
+			b.nodes[e.To].Inbound += e.Count
+		}
+		g.Edges = append(g.Edges, *e)
+	}
+	b.assignClusters()
+	for _, id := range b.order {
+		g.Nodes = append(g.Nodes, *b.nodes[id])
+	}
+	return g
+}
+
+// Walk visits the live blocks reachable from the tree's roots in document
+// order, including database rows, with the ID of their enclosing page (the
+// block itself for pages). Blocks above the first page have no owner and are
+// skipped.
+func Walk(tree *notionclient.Tree, fn func(owner string, id string, block map[string]any, row bool)) {
+	walk(tree, func(_ string, owner string, id string, block map[string]any, row bool) {
+		fn(owner, id, block, row)
+	})
+}
+
+// walk is Walk that also passes the page the traversal came from, which for
+// pages is the page they are shown on.
+func walk(tree *notionclient.Tree, fn func(parent string, owner string, id string, block map[string]any, row bool)) {
+	blocks := tree.Records["block"]
+	rows := notionclient.RowsByCollection(tree.Records)
+
+	seen := map[string]bool{}
+	var visit func(id string, owner string, row bool)
+	visit = func(id string, owner string, row bool) {
+		block := blocks[id]
+		if block == nil || !notionclient.IsAlive(block) || seen[id] {
+			return
+		}
+		seen[id] = true
+		parent := owner
+		if notionclient.IsPageBlock(block) {
+			owner = id
+		}
+		if owner != "" {
+			fn(parent, owner, id, block, row)
+		}
+		for _, child := range notionclient.ContentIDs(block) {
+			visit(child, owner, false)
+		}
+		if cid := notionclient.CollectionIDForBlock(block, tree.Records["collection_view"]); cid != "" {
+			for _, rowID := range rows[cid] {
+				visit(rowID, owner, true)
+			}
+		}
+	}
+	for _, root := range tree.Roots {
+		visit(root, "", false)
+	}
+}
+
+// ParentPage returns the nearest page above a page in the tree.
+func ParentPage(tree *notionclient.Tree, id string) string {
+	cur := tree.Block(id)
+	for depth := 0; depth < 64; depth++ {
+		parentID, _ := cur["parent_id"].(string)
+		switch pt, _ := cur["parent_table"].(string); pt {
+		case "block":
+			cur = tree.Block(parentID)
+		case "collection":
+			collection := tree.Records["collection"][parentID]
+			parentID, _ = collection["parent_id"].(string)
+			cur = tree.Block(parentID)
+		default:
+			return ""
+		}
+		if cur == nil {
+			return ""
+		}
+		if notionclient.IsPageBlock(cur) {
+			return parentID
+		}
+	}
+	return ""
+}
+
+func (b *builder) addPage(id string, block map[string]any, depth int, row bool) {
+	kind := KindPage
+	switch {
+	case row:
+		kind = KindRow
+	case block["type"] == "collection_view_page":
+		kind = KindDatabase
+	}
+	b.nodes[id] = &Node{ID: id, Title: notionclient.PageTitle(b.records, block), Kind: kind, Depth: depth}
+	b.order = append(b.order, id)
+}
+
+func (b *builder) addEdge(from string, to string, typ string) {
+	if from == to {
+		return
+	}
+	key := [3]string{from, to, typ}
+	if e, ok := b.edges[key]; ok {
+		e.Count++
+		return
+	}
+	b.edges[key] = &Edge{From: from, To: to, Type: typ, Count: 1}
+	b.edgeSeq = append(b.edgeSeq, key)
+}
+
+// Reference is a pointer from a block to a page.
+type Reference struct {
+	// Type is Link, Mention or Relation.
+	Type   string
+	Target string
+	// Property is the property key holding a mention or relation.
+	Property string
+}
+
+// BlockReferences returns the link-to-page target, page mentions and
+// relation targets of one block. Relation properties are told apart from
+// mentions by the schema of the row's collection in records.
+func BlockReferences(records map[string]map[string]map[string]any, block map[string]any) []Reference {
+	out := make([]Reference, 0)
+	if target := notionclient.AliasTarget(block); target != "" {
+		out = append(out, Reference{Type: Link, Target: target})
+	}
+
+	var schema map[string]any
+	if pt, _ := block["parent_table"].(string); pt == "collection" {
+		cid, _ := block["parent_id"].(string)
+		schema, _ = records["collection"][cid]["schema"].(map[string]any)
+	}
+	props, _ := block["properties"].(map[string]any)
+	for _, key := range notionclient.SortedKeys(props) {
+		typ := Mention
+		if prop, _ := schema[key].(map[string]any); prop["type"] == "relation" {
+			typ = Relation
+		}
+		for _, target := range notionclient.MentionedPageIDs(props[key]) {
+			out = append(out, Reference{Type: typ, Target: target, Property: key})
+		}
+	}
+	return out
+}
+
+// assignClusters numbers the weakly connected components of the non-child
+// edges, largest first, ties broken by first appearance.
+func (b *builder) assignClusters() {
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(id string) string {
+		p, ok := parent[id]
+		if !ok || p == id {
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+	for _, key := range b.edgeSeq {
+		e := b.edges[key]
+		if e.Type == Child {
+			continue
+		}
+		if a, c := find(e.From), find(e.To); a != c {
+			parent[c] = a
+		}
+	}
+
+	members := map[string][]string{}
+	roots := make([]string, 0)
+	for _, id := range b.order {
+		r := find(id)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], id)
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		return len(members[roots[i]]) > len(members[roots[j]])
+	})
+	for i, r := range roots {
+		for _, id := range members[r] {
+			b.nodes[id].Cluster = i + 1
+		}
+	}
+}
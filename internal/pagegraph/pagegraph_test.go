@@ -0,0 +1,137 @@
+package pagegraph
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+func mention(ids ...string) []any {
+	out := make([]any, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, []any{"‣", []any{[]any{"p", id}}})
+	}
+	return out
+}
+
+func title(s string) map[string]any {
+	return map[string]any{"title": []any{[]any{s}}}
+}
+
+// testTree is a root page with a subpage, a full-page database with two
+// rows, a link-to-page block and mentions in both directions.
+func testTree() *notionclient.Tree {
+	return &notionclient.Tree{
+		Roots: []string{"root"},
+		Records: map[string]map[string]map[string]any{
+			"block": {
+				"root": {"type": "page", "properties": title("Root"), "content": []any{"t1", "sub", "db", "al"}},
+				"t1":   {"type": "text", "properties": map[string]any{"title": mention("sub", "sub")}},
+				"sub":  {"type": "page", "properties": title("Sub"), "content": []any{"s1", "gone"}},
+				"s1":   {"type": "text", "properties": map[string]any{"title": mention("root")}},
+				"gone": {"type": "text", "alive": false, "properties": map[string]any{"title": mention("ext")}},
+				"db":   {"type": "collection_view_page", "collection_id": "coll"},
+				"r1": {
+					"type": "page", "parent_table": "collection", "parent_id": "coll",
+					"properties": map[string]any{"title": []any{[]any{"Row 1"}}, "rel": mention("r2")},
+				},
+				"r2": {"type": "page", "parent_table": "collection", "parent_id": "coll", "properties": title("Row 2")},
+				"al": {"type": "alias", "format": map[string]any{"alias_pointer": map[string]any{"id": "ext"}}},
+			},
+			"collection": {
+				"coll": {"name": []any{[]any{"Tasks"}}, "schema": map[string]any{"rel": map[string]any{"name": "Related", "type": "relation"}}},
+			},
+		},
+		Depth: map[string]int{"root": 0, "sub": 1, "db": 1, "r1": 2, "r2": 2},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	g := Build(testTree())
+
+	wantNodes := []Node{
+		{ID: "root", Title: "Root", Kind: KindPage, Depth: 0, Inbound: 1, Cluster: 1},
+		{ID: "sub", Title: "Sub", Kind: KindPage, Depth: 1, Inbound: 2, Cluster: 1},
+		{ID: "db", Title: "Tasks", Kind: KindDatabase, Depth: 1, Cluster: 3},
+		{ID: "r1", Title: "Row 1", Kind: KindRow, Depth: 2, Cluster: 2},
+		{ID: "r2", Title: "Row 2", Kind: KindRow, Depth: 2, Inbound: 1, Cluster: 2},
+		{ID: "ext", Kind: KindExternal, Depth: -1, Inbound: 1, Cluster: 1},
+	}
+	wantEdges := []Edge{
+		{From: "root", To: "sub", Type: Mention, Count: 2},
+		{From: "root", To: "sub", Type: Child, Count: 1},
+		{From: "sub", To: "root", Type: Mention, Count: 1},
+		{From: "root", To: "db", Type: Child, Count: 1},
+		{From: "db", To: "r1", Type: Child, Count: 1},
+		{From: "r1", To: "r2", Type: Relation, Count: 1},
+		{From: "db", To: "r2", Type: Child, Count: 1},
+		{From: "root", To: "ext", Type: Link, Count: 1},
+	}
+	if g.Root != "root" {
+		t.Errorf("Root = %q, want root", g.Root)
+	}
+	if !reflect.DeepEqual(g.Nodes, wantNodes) {
+		t.Errorf("Nodes =\n%+v\nwant\n%+v", g.Nodes, wantNodes)
+	}
+	if !reflect.DeepEqual(g.Edges, wantEdges) {
+		t.Errorf("Edges =\n%+v\nwant\n%+v", g.Edges, wantEdges)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	var got []string
+	Walk(testTree(), func(owner string, id string, block map[string]any, row bool) {
+		s := owner + ">" + id
+		if row {
+			s += " row"
+		}
+		got = append(got, s)
+	})
+	want := []string{"root>root", "root>t1", "sub>sub", "sub>s1", "db>db", "r1>r1 row", "r2>r2 row", "root>al"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk visited %q, want %q", got, want)
+	}
+}
+
+func TestBlockReferences(t *testing.T) {
+	tree := testTree()
+	tests := []struct {
+		id   string
+		want []Reference
+	}{
+		{"t1", []Reference{{Type: Mention, Target: "sub", Property: "title"}, {Type: Mention, Target: "sub", Property: "title"}}},
+		{"al", []Reference{{Type: Link, Target: "ext"}}},
+		{"r1", []Reference{{Type: Relation, Target: "r2", Property: "rel"}}},
+		{"r2", []Reference{}},
+	}
+	for _, tt := range tests {
+		if got := BlockReferences(tree.Records, tree.Block(tt.id)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BlockReferences(%s) = %+v, want %+v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := &Graph{
+		Root:  "a",
+		Nodes: []Node{{ID: "a", Title: `Say "hi"`, Kind: KindPage, Cluster: 1}, {ID: "b", Kind: KindExternal, Depth: -1, Cluster: 1}},
+		Edges: []Edge{{From: "a", To: "b", Type: Link, Count: 3}},
+	}
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`"a" [label="Say \"hi\"", shape="box", kind="page", cluster=1];`,
+		`"b" [label="b", shape="box", style="dashed", kind="external", cluster=1];`,
+		`"a" -> "b" [type="link", style="dashed", color="royalblue", label="3"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %s\n%s", want, out)
+		}
+	}
+}
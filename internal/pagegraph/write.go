@@ -0,0 +1,145 @@
+package pagegraph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var dotEdgeStyle = map[string]string{
+	Child:    `color="gray50"`,
+	Link:     `style="dashed", color="royalblue"`,
+	Mention:  `style="dotted", color="darkorange"`,
+	Relation: `style="bold", color="forestgreen"`,
+}
+
+var dotNodeStyle = map[string]string{
+	KindPage:     `shape="box"`,
+	KindDatabase: `shape="cylinder"`,
+	KindRow:      `shape="note"`,
+	KindExternal: `shape="box", style="dashed"`,
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func WriteDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph pages {\n")
+	b.WriteString("  rankdir=\"LR\";\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, %s", dotQuote(n.ID), dotQuote(nodeLabel(n)), dotNodeStyle[n.Kind])
+		if n.URL != "" {
+			fmt.Fprintf(&b, ", URL=%s", dotQuote(n.URL))
+		}
+		fmt.Fprintf(&b, ", kind=%s, cluster=%d];\n", dotQuote(n.Kind), n.Cluster)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [type=%s, %s", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Type), dotEdgeStyle[e.Type])
+		if e.Count > 1 {
+			fmt.Fprintf(&b, ", label=\"%d\"", e.Count)
+		}
+		b.WriteString("];\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func nodeLabel(n Node) string {
+	if n.Title != "" {
+		return n.Title
+	}
+	return n.ID
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the graph as GraphML, with node and edge fields as
+// data keys.
+func WriteGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "title", For: "node", Name: "title", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "url", For: "node", Name: "url", Type: "string"},
+			{ID: "depth", For: "node", Name: "depth", Type: "int"},
+			{ID: "inbound", For: "node", Name: "inbound", Type: "int"},
+			{ID: "cluster", For: "node", Name: "cluster", Type: "int"},
+			{ID: "type", For: "edge", Name: "type", Type: "string"},
+			{ID: "count", For: "edge", Name: "count", Type: "int"},
+		},
+		Graph: graphMLGraph{ID: g.Root, EdgeDefault: "directed"},
+	}
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.ID, Data: []graphMLData{
+			{Key: "title", Value: n.Title},
+			{Key: "kind", Value: n.Kind},
+			{Key: "url", Value: n.URL},
+			{Key: "depth", Value: strconv.Itoa(n.Depth)},
+			{Key: "inbound", Value: strconv.Itoa(n.Inbound)},
+			{Key: "cluster", Value: strconv.Itoa(n.Cluster)},
+		}})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.From, Target: e.To, Data: []graphMLData{
+			{Key: "type", Value: e.Type},
+			{Key: "count", Value: strconv.Itoa(e.Count)},
+		}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,590 @@
+// Package render turns a fetched block tree into standalone HTML pages or
+// Markdown.
+package render
+
+import (
+	_ "embed"
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+// Theme is the default stylesheet for rendered pages.
+//
+//go:embed theme.css
+var Theme string
+
+// Options control how links, files and users are rendered.
+type Options struct {
+	// PageHref returns the link target of a page, e.g. a local file for
+	// exported pages and the Notion URL for the rest.
+	PageHref func(id string) string
+	// Asset returns the path to use for a file stored on a block (images,
+	// files, icons, covers). Returning "" keeps the original URL.
+	Asset func(blockID string, url string) string
+	// PageTitle returns the title of a linked page that is not in the tree.
+	PageTitle func(id string) string
+	// UserName returns a display name for a user mention; the user ID is
+	// shown when nil or empty.
+	UserName func(id string) string
+	// Stylesheet is the href of the theme CSS. When empty, Theme is inlined.
+	Stylesheet string
+}
+
+type renderer struct {
+	tree *notionclient.Tree
+	opts Options
+	// rows lists row IDs per collection, oldest first.
+	rows map[string][]string
+}
+
+// Page renders one page of the tree as a complete HTML document. Subpages
+// are rendered as links, not inline.
+func Page(tree *notionclient.Tree, pageID string, opts Options) (string, error) {
+	page := tree.Block(pageID)
+	if page == nil {
+		return "", fmt.Errorf("page %s is not in the fetched tree", pageID)
+	}
+	r := &renderer{tree: tree, opts: opts, rows: notionclient.RowsByCollection(tree.Records)}
+	title := r.pageTitle(pageID)
+	format, _ := page["format"].(map[string]any)
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	if opts.Stylesheet != "" {
+		fmt.Fprintf(&b, "<link rel=\"stylesheet\" href=\"%s\">\n", html.EscapeString(opts.Stylesheet))
+	} else {
+		fmt.Fprintf(&b, "<style>\n%s</style>\n", Theme)
+	}
+	b.WriteString("</head>\n<body>\n")
+
+	classes := []string{"page"}
+	if v, _ := format["page_full_width"].(bool); v {
+		classes = append(classes, "page-full-width")
+	}
+	if v, _ := format["page_small_text"].(bool); v {
+		classes = append(classes, "page-small-text")
+	}
+	fmt.Fprintf(&b, "<article class=\"%s\" id=\"%s\">\n<header>\n", strings.Join(classes, " "), anchor(pageID))
+	if cover, _ := format["page_cover"].(string); cover != "" {
+		fmt.Fprintf(&b, "<img class=\"page-cover\" src=\"%s\" alt=\"\">\n", html.EscapeString(r.asset(pageID, cover)))
+	}
+	if icon := r.icon(pageID, page, "page-icon"); icon != "" {
+		b.WriteString(icon + "\n")
+	}
+	fmt.Fprintf(&b, "<h1 class=\"page-title\">%s</h1>\n</header>\n<div class=\"page-body\">\n", html.EscapeString(title))
+	if page["type"] == "collection_view_page" {
+		r.collection(&b, pageID, page, false)
+	} else {
+		r.blocks(&b, notionclient.ContentIDs(page))
+	}
+	b.WriteString("</div>\n</article>\n</body>\n</html>\n")
+	return b.String(), nil
+}
+
+// listTypes maps list block types to their list element.
+var listTypes = map[string]string{
+	"bulleted_list": "ul",
+	"numbered_list": "ol",
+	"to_do":         "ul",
+}
+
+// blocks renders sibling blocks, grouping consecutive list items into one
+// list element.
+func (r *renderer) blocks(b *strings.Builder, ids []string) {
+	openList := ""
+	for _, id := range ids {
+		block := r.tree.Block(id)
+		if block == nil || !notionclient.IsAlive(block) {
+			continue
+		}
+		typ, _ := block["type"].(string)
+		if openList != "" && typ != openList {
+			fmt.Fprintf(b, "</%s>\n", listTypes[openList])
+			openList = ""
+		}
+		if tag, ok := listTypes[typ]; ok && openList == "" {
+			class := ""
+			if typ == "to_do" {
+				class = ` class="to-do-list"`
+			}
+			fmt.Fprintf(b, "<%s%s>\n", tag, class)
+			openList = typ
+		}
+		r.block(b, id, block)
+	}
+	if openList != "" {
+		fmt.Fprintf(b, "</%s>\n", listTypes[openList])
+	}
+}
+
+// children renders the nested content of a non-page block.
+func (r *renderer) children(b *strings.Builder, block map[string]any) {
+	if notionclient.IsPageBlock(block) {
+		return
+	}
+	r.blocks(b, notionclient.ContentIDs(block))
+}
+
+var headingTags = map[string]string{
+	"header":         "h2",
+	"sub_header":     "h3",
+	"sub_sub_header": "h4",
+}
+
+// embedTypes are blocks showing a third-party URL; offline they become
+// links.
+var embedTypes = map[string]bool{
+	"embed": true, "link_preview": true, "figma": true, "maps": true,
+	"codepen": true, "gist": true, "tweet": true, "drive": true,
+	"framer": true, "typeform": true, "miro": true, "excalidraw": true,
+	"whimsical": true, "replit": true, "loom": true,
+}
+
+func (r *renderer) block(b *strings.Builder, id string, block map[string]any) {
+	typ, _ := block["type"].(string)
+	props, _ := block["properties"].(map[string]any)
+	format, _ := block["format"].(map[string]any)
+	title := r.richText(props["title"])
+	color, _ := format["block_color"].(string)
+	classAttr := ""
+	if class := colorClass(color); class != "" {
+		classAttr = ` class="` + class + `"`
+	}
+
+	switch {
+	case typ == "text":
+		fmt.Fprintf(b, "<p%s>%s</p>\n", classAttr, title)
+		if len(notionclient.ContentIDs(block)) > 0 {
+			b.WriteString("<div class=\"indent\">\n")
+			r.children(b, block)
+			b.WriteString("</div>\n")
+		}
+	case headingTags[typ] != "":
+		tag := headingTags[typ]
+		heading := fmt.Sprintf("<%s id=\"%s\"%s>%s</%s>", tag, anchor(id), classAttr, title, tag)
+		if toggleable, _ := format["toggleable"].(bool); toggleable {
+			fmt.Fprintf(b, "<details class=\"toggle-heading\">\n<summary>%s</summary>\n", heading)
+			r.children(b, block)
+			b.WriteString("</details>\n")
+		} else {
+			b.WriteString(heading + "\n")
+		}
+	case typ == "bulleted_list" || typ == "numbered_list":
+		fmt.Fprintf(b, "<li%s>%s", classAttr, title)
+		r.nested(b, block)
+		b.WriteString("</li>\n")
+	case typ == "to_do":
+		checked := ""
+		if notionclient.PlainText(props["checked"]) == "Yes" {
+			checked = " checked"
+		}
+		fmt.Fprintf(b, "<li%s><input type=\"checkbox\" disabled%s> <span>%s</span>", classAttr, checked, title)
+		r.nested(b, block)
+		b.WriteString("</li>\n")
+	case typ == "toggle":
+		fmt.Fprintf(b, "<details%s>\n<summary>%s</summary>\n<div class=\"toggle-body\">\n", classAttr, title)
+		r.children(b, block)
+		b.WriteString("</div>\n</details>\n")
+	case typ == "quote":
+		fmt.Fprintf(b, "<blockquote%s>\n<p>%s</p>\n", classAttr, title)
+		r.children(b, block)
+		b.WriteString("</blockquote>\n")
+	case typ == "callout":
+		fmt.Fprintf(b, "<aside class=\"%s\">\n", strings.TrimSpace("callout "+colorClass(color)))
+		if icon := r.icon(id, block, "callout-icon"); icon != "" {
+			b.WriteString(icon + "\n")
+		}
+		fmt.Fprintf(b, "<div class=\"callout-body\">\n<p>%s</p>\n", title)
+		r.children(b, block)
+		b.WriteString("</div>\n</aside>\n")
+	case typ == "divider":
+		b.WriteString("<hr>\n")
+	case typ == "code":
+		lang := notionclient.PlainText(props["language"])
+		fmt.Fprintf(b, "<figure class=\"code\">\n<pre><code class=\"language-%s\">%s</code></pre>\n", languageClass(lang), html.EscapeString(notionclient.PlainText(props["title"])))
+		r.caption(b, props)
+		b.WriteString("</figure>\n")
+	case typ == "equation":
+		fmt.Fprintf(b, "<div class=\"math math-display\">%s</div>\n", Math(notionclient.PlainText(props["title"]), true))
+	case typ == "column_list":
+		b.WriteString("<div class=\"columns\">\n")
+		r.children(b, block)
+		b.WriteString("</div>\n")
+	case typ == "column":
+		ratio, _ := format["column_ratio"].(float64)
+		if ratio <= 0 {
+			ratio = 1
+		}
+		fmt.Fprintf(b, "<div class=\"column\" style=\"flex: %g 1 0\">\n", ratio)
+		r.children(b, block)
+		b.WriteString("</div>\n")
+	case typ == "table":
+		r.table(b, block)
+	case typ == "image":
+		src := r.asset(id, fileSource(block))
+		style := ""
+		if w, _ := format["block_width"].(float64); w > 0 {
+			style = fmt.Sprintf(" style=\"max-width: %gpx\"", w)
+		}
+		fmt.Fprintf(b, "<figure class=\"image\">\n<img src=\"%s\" alt=\"%s\" loading=\"lazy\"%s>\n", html.EscapeString(safeURL(src)), html.EscapeString(notionclient.PlainText(props["caption"])), style)
+		r.caption(b, props)
+		b.WriteString("</figure>\n")
+	case typ == "video" || typ == "audio":
+		raw := fileSource(block)
+		src := r.asset(id, raw)
+		if src == raw && !notionclient.IsNotionFileURL(raw) {
+			// Hosted elsewhere (YouTube, Vimeo, ...): link instead of embed.
+			fmt.Fprintf(b, "<p class=\"embed\"><a%s>%s</a></p>\n", hrefAttr(raw), html.EscapeString(raw))
+			break
+		}
+		fmt.Fprintf(b, "<figure class=\"%s\">\n<%s controls src=\"%s\"></%s>\n", typ, typ, html.EscapeString(safeURL(src)), typ)
+		r.caption(b, props)
+		b.WriteString("</figure>\n")
+	case typ == "file" || typ == "pdf":
+		src := r.asset(id, fileSource(block))
+		name := notionclient.PlainText(props["title"])
+		if name == "" {
+			name = src
+		}
+		fmt.Fprintf(b, "<p class=\"file\"><a%s>%s</a></p>\n", hrefAttr(src), html.EscapeString(name))
+	case typ == "bookmark":
+		link := notionclient.PlainText(props["link"])
+		label := notionclient.PlainText(props["title"])
+		if label == "" {
+			label = link
+		}
+		fmt.Fprintf(b, "<a class=\"bookmark\"%s>\n<span class=\"bookmark-title\">%s</span>\n", hrefAttr(link), html.EscapeString(label))
+		if desc := notionclient.PlainText(props["description"]); desc != "" {
+			fmt.Fprintf(b, "<span class=\"bookmark-description\">%s</span>\n", html.EscapeString(desc))
+		}
+		fmt.Fprintf(b, "<span class=\"bookmark-url\">%s</span>\n</a>\n", html.EscapeString(link))
+	case embedTypes[typ]:
+		src := fileSource(block)
+		fmt.Fprintf(b, "<p class=\"embed\"><a%s>%s</a></p>\n", hrefAttr(src), html.EscapeString(src))
+	case typ == "page" || typ == "collection_view_page":
+		r.pageLink(b, id)
+	case typ == "alias":
+		r.pageLink(b, notionclient.AliasTarget(block))
+	case typ == "collection_view":
+		r.collection(b, id, block, true)
+	case typ == "table_of_contents":
+		r.tableOfContents(b, id)
+	case typ == "transclusion_container":
+		b.WriteString("<div class=\"synced-block\">\n")
+		r.children(b, block)
+		b.WriteString("</div>\n")
+	case typ == "transclusion_reference":
+		pointer, _ := format["transclusion_reference_pointer"].(map[string]any)
+		sourceID, _ := pointer["id"].(string)
+		if source := r.tree.Block(sourceID); source != nil {
+			b.WriteString("<div class=\"synced-block\">\n")
+			r.children(b, source)
+			b.WriteString("</div>\n")
+		}
+	case typ == "breadcrumb":
+	default:
+		if title != "" {
+			fmt.Fprintf(b, "<p class=\"unsupported\" data-type=\"%s\">%s</p>\n", html.EscapeString(typ), title)
+		} else {
+			fmt.Fprintf(b, "<!-- unsupported block type %s -->\n", html.EscapeString(typ))
+		}
+		r.children(b, block)
+	}
+}
+
+// nested renders the children of a list item inside it.
+func (r *renderer) nested(b *strings.Builder, block map[string]any) {
+	if len(notionclient.ContentIDs(block)) == 0 {
+		return
+	}
+	b.WriteString("\n")
+	r.children(b, block)
+}
+
+func (r *renderer) caption(b *strings.Builder, props map[string]any) {
+	if caption := r.richText(props["caption"]); caption != "" {
+		fmt.Fprintf(b, "<figcaption>%s</figcaption>\n", caption)
+	}
+}
+
+func (r *renderer) pageLink(b *strings.Builder, id string) {
+	if id == "" {
+		return
+	}
+	icon := ""
+	if page := r.tree.Block(id); page != nil {
+		icon = r.icon(id, page, "page-link-icon")
+	}
+	fmt.Fprintf(b, "<p class=\"page-link\"><a href=\"%s\">%s<span>%s</span></a></p>\n", html.EscapeString(r.pageHref(id)), icon, html.EscapeString(r.pageTitle(id)))
+}
+
+// table renders a simple table block from its table_row children.
+func (r *renderer) table(b *strings.Builder, block map[string]any) {
+	format, _ := block["format"].(map[string]any)
+	columns := make([]string, 0)
+	for _, c := range asSlice(format["table_block_column_order"]) {
+		if s, ok := c.(string); ok {
+			columns = append(columns, s)
+		}
+	}
+	colHeader, _ := format["table_block_column_header"].(bool)
+	rowHeader, _ := format["table_block_row_header"].(bool)
+
+	b.WriteString("<table class=\"simple-table\">\n")
+	for i, rowID := range notionclient.ContentIDs(block) {
+		row := r.tree.Block(rowID)
+		if row == nil || !notionclient.IsAlive(row) {
+			continue
+		}
+		props, _ := row["properties"].(map[string]any)
+		b.WriteString("<tr>")
+		for j, col := range columns {
+			tag := "td"
+			if (colHeader && i == 0) || (rowHeader && j == 0) {
+				tag = "th"
+			}
+			fmt.Fprintf(b, "<%s>%s</%s>", tag, r.richText(props[col]), tag)
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+// collection renders a database as a table of its rows, with the columns
+// of its first view.
+func (r *renderer) collection(b *strings.Builder, id string, block map[string]any, showTitle bool) {
+	cid := notionclient.CollectionIDForBlock(block, r.tree.Records["collection_view"])
+	coll := r.tree.Records["collection"][cid]
+	if coll == nil {
+		fmt.Fprintf(b, "<!-- database %s was not fetched -->\n", html.EscapeString(id))
+		return
+	}
+	schema, _ := coll["schema"].(map[string]any)
+	columns := r.viewColumns(block, schema)
+
+	b.WriteString("<figure class=\"collection\">\n")
+	if showTitle {
+		fmt.Fprintf(b, "<figcaption class=\"collection-title\">%s</figcaption>\n", r.richText(coll["name"]))
+	}
+	b.WriteString("<table>\n<thead><tr>")
+	for _, key := range columns {
+		prop, _ := schema[key].(map[string]any)
+		name, _ := prop["name"].(string)
+		fmt.Fprintf(b, "<th>%s</th>", html.EscapeString(name))
+	}
+	b.WriteString("</tr></thead>\n<tbody>\n")
+	for _, rowID := range r.rows[cid] {
+		row := r.tree.Block(rowID)
+		props, _ := row["properties"].(map[string]any)
+		b.WriteString("<tr>")
+		for _, key := range columns {
+			prop, _ := schema[key].(map[string]any)
+			cell := r.richText(props[key])
+			switch prop["type"] {
+			case "title":
+				cell = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(r.pageHref(rowID)), html.EscapeString(r.pageTitle(rowID)))
+			case "checkbox":
+				if notionclient.PlainText(props[key]) == "Yes" {
+					cell = "✓"
+				}
+			}
+			fmt.Fprintf(b, "<td>%s</td>", cell)
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n</figure>\n")
+}
+
+// viewColumns returns the visible properties of the block's first view in
+// view order, or the title followed by the other properties by name.
+func (r *renderer) viewColumns(block map[string]any, schema map[string]any) []string {
+	columns := make([]string, 0)
+	for _, viewID := range asSlice(block["view_ids"]) {
+		id, _ := viewID.(string)
+		view := r.tree.Records["collection_view"][id]
+		format, _ := view["format"].(map[string]any)
+		for _, p := range asSlice(format["table_properties"]) {
+			m, _ := p.(map[string]any)
+			key, _ := m["property"].(string)
+			if visible, ok := m["visible"].(bool); (!ok || visible) && schema[key] != nil {
+				columns = append(columns, key)
+			}
+		}
+		break
+	}
+	if len(columns) > 0 {
+		return columns
+	}
+
+	keys := notionclient.SortedKeys(schema)
+	field := func(k string, f string) string {
+		prop, _ := schema[k].(map[string]any)
+		s, _ := prop[f].(string)
+		return s
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		ti, tj := field(keys[i], "type") == "title", field(keys[j], "type") == "title"
+		if ti != tj {
+			return ti
+		}
+		return field(keys[i], "name") < field(keys[j], "name")
+	})
+	return keys
+}
+
+// tableOfContents lists the headings of the page the block is on.
+func (r *renderer) tableOfContents(b *strings.Builder, id string) {
+	page := r.enclosingPage(id)
+	b.WriteString("<nav class=\"table-of-contents\">\n")
+	var visit func(ids []string)
+	visit = func(ids []string) {
+		for _, cid := range ids {
+			block := r.tree.Block(cid)
+			if block == nil || !notionclient.IsAlive(block) || notionclient.IsPageBlock(block) {
+				continue
+			}
+			typ, _ := block["type"].(string)
+			if tag := headingTags[typ]; tag != "" {
+				fmt.Fprintf(b, "<a class=\"toc-%s\" href=\"#%s\">%s</a>\n", tag, anchor(cid), html.EscapeString(notionclient.BlockTitle(block)))
+			}
+			visit(notionclient.ContentIDs(block))
+		}
+	}
+	visit(notionclient.ContentIDs(r.tree.Block(page)))
+	b.WriteString("</nav>\n")
+}
+
+func (r *renderer) enclosingPage(id string) string {
+	for depth := 0; depth < 64; depth++ {
+		block := r.tree.Block(id)
+		if block == nil || notionclient.IsPageBlock(block) {
+			return id
+		}
+		id, _ = block["parent_id"].(string)
+	}
+	return id
+}
+
+// icon renders a page or callout icon: an emoji, or an image for uploaded
+// and custom icons.
+func (r *renderer) icon(id string, block map[string]any, class string) string {
+	format, _ := block["format"].(map[string]any)
+	icon, _ := format["page_icon"].(string)
+	switch {
+	case icon == "":
+		return ""
+	case strings.HasPrefix(icon, "http") || strings.HasPrefix(icon, "/") || strings.HasPrefix(icon, "attachment:"):
+		return fmt.Sprintf("<img class=\"%s\" src=\"%s\" alt=\"\">", class, html.EscapeString(r.asset(id, icon)))
+	}
+	return fmt.Sprintf("<span class=\"%s\">%s</span>", class, html.EscapeString(icon))
+}
+
+func (r *renderer) asset(blockID string, raw string) string {
+	if raw == "" || r.opts.Asset == nil {
+		return raw
+	}
+	if local := r.opts.Asset(blockID, raw); local != "" {
+		return local
+	}
+	return raw
+}
+
+func (r *renderer) pageTitle(id string) string {
+	block := r.tree.Block(id)
+	if block == nil && r.opts.PageTitle != nil {
+		block = map[string]any{"properties": map[string]any{"title": []any{[]any{r.opts.PageTitle(id)}}}}
+	}
+	title := notionclient.PageTitle(r.tree.Records, block)
+	if title == "" {
+		return "Untitled"
+	}
+	return title
+}
+
+func (r *renderer) pageHref(id string) string {
+	if r.opts.PageHref == nil {
+		return "#" + anchor(id)
+	}
+	return r.opts.PageHref(id)
+}
+
+// linkHref maps links to Notion pages ("/<id>" or notion.so URLs) through
+// PageHref and passes other links through safeURL.
+func (r *renderer) linkHref(link string) string {
+	if strings.HasPrefix(link, "/") || strings.Contains(link, "notion.so/") || strings.Contains(link, "notion.site/") {
+		if id, err := notionclient.ParsePageID(link); err == nil {
+			return r.pageHref(id)
+		}
+	}
+	return safeURL(link)
+}
+
+// safeURL returns u if it is an http, https or mailto URL or a relative
+// link, and "" otherwise, so javascript: and other schemes from page content
+// never become live links.
+func safeURL(u string) string {
+	s := strings.TrimSpace(u)
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto":
+		return s
+	}
+	return ""
+}
+
+// hrefAttr returns ` href="u"`, or "" when safeURL rejects u.
+func hrefAttr(u string) string {
+	if s := safeURL(u); s != "" {
+		return ` href="` + html.EscapeString(s) + `"`
+	}
+	return ""
+}
+
+func (r *renderer) userName(id string) string {
+	if r.opts.UserName != nil {
+		if name := r.opts.UserName(id); name != "" {
+			return name
+		}
+	}
+	return id
+}
+
+// fileSource returns the URL of a media or embed block.
+func fileSource(block map[string]any) string {
+	format, _ := block["format"].(map[string]any)
+	if src, _ := format["display_source"].(string); src != "" {
+		return src
+	}
+	props, _ := block["properties"].(map[string]any)
+	return notionclient.PlainText(props["source"])
+}
+
+// languageClass turns a Notion code language ("Plain Text", "C++") into a
+// highlighter class suffix ("plaintext", "cpp").
+func languageClass(lang string) string {
+	s := strings.ToLower(strings.TrimSpace(lang))
+	if s == "" {
+		return "plaintext"
+	}
+	s = strings.NewReplacer(" ", "", "+", "p", "#", "sharp", "/", "-").Replace(s)
+	return html.EscapeString(s)
+}
+
+func anchor(id string) string {
+	return "b-" + strings.ReplaceAll(id, "-", "")
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://example.com/a?b=c", "https://example.com/a?b=c"},
+		{"HTTP://example.com", "HTTP://example.com"},
+		{"mailto:a@example.com", "mailto:a@example.com"},
+		{"other.html#x", "other.html#x"},
+		{" /relative ", "/relative"},
+		{"//example.com", "//example.com"},
+		{"javascript:alert(1)", ""},
+		{" JavaScript:alert(1)", ""},
+		{"java\tscript:alert(1)", ""},
+		{"data:text/html,<script>alert(1)</script>", ""},
+		{"vbscript:msgbox", ""},
+	}
+	for _, tt := range tests {
+		if got := safeURL(tt.in); got != tt.want {
+			t.Errorf("safeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPageDropsUnsafeLinks(t *testing.T) {
+	tree := &notionclient.Tree{
+		Roots: []string{"p"},
+		Records: map[string]map[string]map[string]any{"block": {
+			"p": {"type": "page", "properties": map[string]any{"title": []any{[]any{"Page"}}}, "content": []any{"t", "bm", "ok"}},
+			"t": {"type": "text", "properties": map[string]any{"title": []any{
+				[]any{"click", []any{[]any{"a", "javascript:alert(1)"}}},
+			}}},
+			"bm": {"type": "bookmark", "properties": map[string]any{"link": []any{[]any{"javascript:alert(2)"}}}},
+			"ok": {"type": "text", "properties": map[string]any{"title": []any{
+				[]any{"site", []any{[]any{"a", "https://example.com"}}},
+			}}},
+		}},
+	}
+	out, err := Page(tree, "p", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, `href="javascript`) {
+		t.Errorf("output has a live javascript: link:\n%s", out)
+	}
+	if !strings.Contains(out, `<a href="https://example.com">site</a>`) {
+		t.Errorf("output lost the https link:\n%s", out)
+	}
+	if !strings.Contains(out, "<p>click</p>") {
+		t.Errorf("unsafe link text missing:\n%s", out)
+	}
+}
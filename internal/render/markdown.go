@@ -0,0 +1,477 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+// Markdown renders one page of the tree as CommonMark with GitHub-style
+// tables. The title is left to the caller (front matter); subpages are
+// rendered as links.
+func Markdown(tree *notionclient.Tree, pageID string, opts Options) (string, error) {
+	page := tree.Block(pageID)
+	if page == nil {
+		return "", fmt.Errorf("page %s is not in the fetched tree", pageID)
+	}
+	r := &renderer{tree: tree, opts: opts, rows: notionclient.RowsByCollection(tree.Records)}
+	var body string
+	if page["type"] == "collection_view_page" {
+		body = r.mdCollection(pageID, page, false)
+	} else {
+		body = r.mdBlocks(notionclient.ContentIDs(page))
+	}
+	if body == "" {
+		return "", nil
+	}
+	return body + "\n", nil
+}
+
+// mdListTypes are the blocks rendered as list items; consecutive items of
+// one type form a tight list.
+var mdListTypes = map[string]bool{
+	"bulleted_list": true,
+	"numbered_list": true,
+	"to_do":         true,
+	"toggle":        true,
+}
+
+// mdBlocks renders sibling blocks separated by blank lines.
+func (r *renderer) mdBlocks(ids []string) string {
+	var b strings.Builder
+	prev := ""
+	for _, id := range ids {
+		block := r.tree.Block(id)
+		if block == nil || !notionclient.IsAlive(block) {
+			continue
+		}
+		chunk := r.mdBlock(id, block)
+		if chunk == "" {
+			continue
+		}
+		typ, _ := block["type"].(string)
+		if b.Len() > 0 {
+			if typ == prev && mdListTypes[typ] {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(chunk)
+		prev = typ
+	}
+	return b.String()
+}
+
+// mdChildren renders the nested content of a non-page block.
+func (r *renderer) mdChildren(block map[string]any) string {
+	if notionclient.IsPageBlock(block) {
+		return ""
+	}
+	return r.mdBlocks(notionclient.ContentIDs(block))
+}
+
+var mdHeadings = map[string]string{
+	"header":         "## ",
+	"sub_header":     "### ",
+	"sub_sub_header": "#### ",
+}
+
+func (r *renderer) mdBlock(id string, block map[string]any) string {
+	typ, _ := block["type"].(string)
+	props, _ := block["properties"].(map[string]any)
+	format, _ := block["format"].(map[string]any)
+	title := r.mdRichText(props["title"])
+
+	switch {
+	case typ == "text":
+		return joinChunks(mdLineStart(title), r.mdChildren(block))
+	case mdHeadings[typ] != "":
+		return joinChunks(mdHeadings[typ]+title, r.mdChildren(block))
+	case typ == "bulleted_list" || typ == "toggle":
+		return r.mdItem("- ", "  ", title, block)
+	case typ == "numbered_list":
+		return r.mdItem("1. ", "   ", title, block)
+	case typ == "to_do":
+		marker := "- [ ] "
+		if notionclient.PlainText(props["checked"]) == "Yes" {
+			marker = "- [x] "
+		}
+		return r.mdItem(marker, "  ", title, block)
+	case typ == "quote":
+		return prefixLines(joinChunks(title, r.mdChildren(block)), "> ")
+	case typ == "callout":
+		if icon, _ := format["page_icon"].(string); icon != "" && !strings.Contains(icon, "/") && !strings.HasPrefix(icon, "attachment:") {
+			title = icon + " " + title
+		}
+		return prefixLines(joinChunks(title, r.mdChildren(block)), "> ")
+	case typ == "divider":
+		return "---"
+	case typ == "code":
+		code := notionclient.PlainText(props["title"])
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		lang := languageClass(notionclient.PlainText(props["language"]))
+		if lang == "plaintext" {
+			lang = ""
+		}
+		return joinChunks(fence+lang+"\n"+code+"\n"+fence, r.mdCaption(props))
+	case typ == "equation":
+		return "$$\n" + notionclient.PlainText(props["title"]) + "\n$$"
+	case typ == "column_list" || typ == "column" || typ == "transclusion_container":
+		return r.mdChildren(block)
+	case typ == "transclusion_reference":
+		pointer, _ := format["transclusion_reference_pointer"].(map[string]any)
+		sourceID, _ := pointer["id"].(string)
+		if source := r.tree.Block(sourceID); source != nil {
+			return r.mdChildren(source)
+		}
+		return ""
+	case typ == "table":
+		return r.mdTable(block)
+	case typ == "image":
+		alt := mdEscape(notionclient.PlainText(props["caption"]))
+		if src := safeURL(r.asset(id, fileSource(block))); src != "" {
+			return "![" + alt + "](" + mdURL(src) + ")"
+		}
+		return alt
+	case typ == "video" || typ == "audio" || typ == "file" || typ == "pdf":
+		src := r.asset(id, fileSource(block))
+		name := notionclient.PlainText(props["title"])
+		if name == "" {
+			name = src
+		}
+		return joinChunks(mdLink(mdEscape(name), src), r.mdCaption(props))
+	case typ == "bookmark":
+		link := notionclient.PlainText(props["link"])
+		label := notionclient.PlainText(props["title"])
+		if label == "" {
+			label = link
+		}
+		out := mdLink(mdEscape(label), link)
+		if desc := notionclient.PlainText(props["description"]); desc != "" {
+			out += "  \n" + mdEscape(desc)
+		}
+		return out
+	case embedTypes[typ]:
+		src := fileSource(block)
+		return mdLink(mdEscape(src), src)
+	case typ == "page" || typ == "collection_view_page":
+		return r.mdPageLink(id)
+	case typ == "alias":
+		return r.mdPageLink(notionclient.AliasTarget(block))
+	case typ == "collection_view":
+		return r.mdCollection(id, block, true)
+	case typ == "table_of_contents" || typ == "breadcrumb":
+		// Static site generators build their own navigation.
+		return ""
+	}
+	return joinChunks(mdLineStart(title), r.mdChildren(block))
+}
+
+// mdItem renders a list item; continuation lines and children are indented
+// to the item's content column.
+func (r *renderer) mdItem(marker string, indent string, text string, block map[string]any) string {
+	out := marker
+	if first, rest, ok := strings.Cut(text, "\n"); ok {
+		out += first + "\n" + prefixLines(rest, indent)
+	} else {
+		out += text
+	}
+	ids := notionclient.ContentIDs(block)
+	children := r.mdChildren(block)
+	if children == "" {
+		return out
+	}
+	sep := "\n\n"
+	for _, id := range ids {
+		if child := r.tree.Block(id); child != nil && notionclient.IsAlive(child) {
+			if typ, _ := child["type"].(string); mdListTypes[typ] {
+				sep = "\n"
+			}
+			break
+		}
+	}
+	return out + sep + prefixLines(children, indent)
+}
+
+func (r *renderer) mdCaption(props map[string]any) string {
+	if caption := r.mdRichText(props["caption"]); caption != "" {
+		return "_" + caption + "_"
+	}
+	return ""
+}
+
+func (r *renderer) mdPageLink(id string) string {
+	if id == "" {
+		return ""
+	}
+	label := mdEscape(r.pageTitle(id))
+	if page := r.tree.Block(id); page != nil {
+		format, _ := page["format"].(map[string]any)
+		if icon, _ := format["page_icon"].(string); icon != "" && !strings.Contains(icon, "/") && !strings.HasPrefix(icon, "attachment:") {
+			label = icon + " " + label
+		}
+	}
+	return "[" + label + "](" + mdURL(r.pageHref(id)) + ")"
+}
+
+// mdTable renders a simple table block; the first row is the header since
+// Markdown tables need one.
+func (r *renderer) mdTable(block map[string]any) string {
+	format, _ := block["format"].(map[string]any)
+	columns := make([]string, 0)
+	for _, c := range asSlice(format["table_block_column_order"]) {
+		if s, ok := c.(string); ok {
+			columns = append(columns, s)
+		}
+	}
+	if len(columns) == 0 {
+		return ""
+	}
+	rows := make([][]string, 0)
+	for _, rowID := range notionclient.ContentIDs(block) {
+		row := r.tree.Block(rowID)
+		if row == nil || !notionclient.IsAlive(row) {
+			continue
+		}
+		props, _ := row["properties"].(map[string]any)
+		cells := make([]string, 0, len(columns))
+		for _, col := range columns {
+			cells = append(cells, r.mdRichText(props[col]))
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return mdPipeTable(rows[0], rows[1:])
+}
+
+// mdCollection renders a database as a table of its rows, with the columns
+// of its first view.
+func (r *renderer) mdCollection(id string, block map[string]any, showTitle bool) string {
+	cid := notionclient.CollectionIDForBlock(block, r.tree.Records["collection_view"])
+	coll := r.tree.Records["collection"][cid]
+	if coll == nil {
+		return ""
+	}
+	schema, _ := coll["schema"].(map[string]any)
+	columns := r.viewColumns(block, schema)
+	if len(columns) == 0 {
+		return ""
+	}
+
+	header := make([]string, 0, len(columns))
+	for _, key := range columns {
+		prop, _ := schema[key].(map[string]any)
+		name, _ := prop["name"].(string)
+		header = append(header, mdEscape(name))
+	}
+	rows := make([][]string, 0, len(r.rows[cid]))
+	for _, rowID := range r.rows[cid] {
+		row := r.tree.Block(rowID)
+		props, _ := row["properties"].(map[string]any)
+		cells := make([]string, 0, len(columns))
+		for _, key := range columns {
+			prop, _ := schema[key].(map[string]any)
+			cell := r.mdRichText(props[key])
+			switch prop["type"] {
+			case "title":
+				cell = "[" + mdEscape(r.pageTitle(rowID)) + "](" + mdURL(r.pageHref(rowID)) + ")"
+			case "checkbox":
+				cell = ""
+				if notionclient.PlainText(props[key]) == "Yes" {
+					cell = "✓"
+				}
+			}
+			cells = append(cells, cell)
+		}
+		rows = append(rows, cells)
+	}
+	table := mdPipeTable(header, rows)
+	if showTitle {
+		if name := r.mdRichText(coll["name"]); name != "" {
+			return "**" + name + "**\n\n" + table
+		}
+	}
+	return table
+}
+
+func mdPipeTable(header []string, rows [][]string) string {
+	line := func(cells []string) string {
+		for i, c := range cells {
+			cells[i] = strings.ReplaceAll(c, "\\\n", " ")
+		}
+		return "| " + strings.Join(cells, " | ") + " |"
+	}
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	out := []string{line(header), line(sep)}
+	for _, row := range rows {
+		out = append(out, line(row))
+	}
+	return strings.Join(out, "\n")
+}
+
+// mdRichText renders Notion rich text segments as inline Markdown.
+func (r *renderer) mdRichText(v any) string {
+	segments, _ := v.([]any)
+	var b strings.Builder
+	for _, seg := range segments {
+		parts, _ := seg.([]any)
+		if len(parts) == 0 {
+			continue
+		}
+		text, _ := parts[0].(string)
+		var decorations []any
+		if len(parts) > 1 {
+			decorations, _ = parts[1].([]any)
+		}
+		b.WriteString(r.mdSegment(text, decorations))
+	}
+	return b.String()
+}
+
+func (r *renderer) mdSegment(text string, decorations []any) string {
+	out := ""
+	var link, marks string
+	code, special := false, false
+	for _, d := range decorations {
+		dec, _ := d.([]any)
+		if len(dec) == 0 {
+			continue
+		}
+		tag, _ := dec[0].(string)
+		arg := ""
+		if len(dec) > 1 {
+			arg, _ = dec[1].(string)
+		}
+		switch tag {
+		case "b":
+			marks = "**" + marks
+		case "i":
+			marks += "*"
+		case "s":
+			marks = "~~" + marks
+		case "c":
+			code = true
+		case "a":
+			link = arg
+		case "e":
+			out, special = "$"+arg+"$", true
+		case "p":
+			out, special = "["+mdEscape(r.pageTitle(arg))+"]("+mdURL(r.pageHref(arg))+")", true
+		case "u":
+			out, special = "@"+mdEscape(r.userName(arg)), true
+		case "d":
+			date, _ := dec[1].(map[string]any)
+			label, _ := date["start_date"].(string)
+			if end, _ := date["end_date"].(string); end != "" {
+				label += " → " + end
+			}
+			out, special = label, true
+		}
+	}
+	if !special {
+		if code {
+			out = codeSpan(text)
+		} else {
+			out = strings.ReplaceAll(mdEscape(text), "\n", "\\\n")
+		}
+	}
+	if marks != "" {
+		// Emphasis must not start or end with whitespace.
+		core := strings.TrimSpace(out)
+		if core != "" {
+			start := strings.Index(out, core)
+			closing := reverseMarks(marks)
+			out = out[:start] + marks + core + closing + out[start+len(core):]
+		}
+	}
+	if link != "" {
+		out = mdLink(out, r.linkHref(link))
+	}
+	return out
+}
+
+func reverseMarks(marks string) string {
+	out := []byte(marks)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func codeSpan(text string) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " " + text + " " + fence
+	}
+	return fence + text + fence
+}
+
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `|`, `\|`, `~`, `\~`,
+)
+
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+var mdBlockStart = regexp.MustCompile(`^(#|[-+=]|\d+[.)])`)
+
+// mdLineStart escapes text that would otherwise start a heading or list.
+func mdLineStart(s string) string {
+	if m := mdBlockStart.FindString(s); m != "" {
+		return m[:len(m)-1] + `\` + m[len(m)-1:]
+	}
+	return s
+}
+
+// mdLink links label to u, or returns the bare label when safeURL rejects u.
+func mdLink(label string, u string) string {
+	if u = safeURL(u); u == "" {
+		return label
+	}
+	return "[" + label + "](" + mdURL(u) + ")"
+}
+
+// mdURL makes a link destination safe to use without angle brackets.
+func mdURL(u string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(u)
+}
+
+func joinChunks(chunks ...string) string {
+	out := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		if c != "" {
+			out = append(out, c)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// prefixLines prefixes every line with p; blank lines get p without
+// trailing spaces.
+func prefixLines(s string, p string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.TrimRight(p, " ")
+		} else {
+			lines[i] = p + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,37 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+func TestMarkdownDropsUnsafeLinks(t *testing.T) {
+	tree := &notionclient.Tree{
+		Roots: []string{"p"},
+		Records: map[string]map[string]map[string]any{"block": {
+			"p": {"type": "page", "properties": map[string]any{"title": []any{[]any{"Page"}}}, "content": []any{"t", "bm", "ok"}},
+			"t": {"type": "text", "properties": map[string]any{"title": []any{
+				[]any{"click", []any{[]any{"a", "javascript:alert(1)"}}},
+			}}},
+			"bm": {"type": "bookmark", "properties": map[string]any{"link": []any{[]any{"javascript:alert(2)"}}}},
+			"ok": {"type": "text", "properties": map[string]any{"title": []any{
+				[]any{"site", []any{[]any{"a", "https://example.com"}}},
+			}}},
+		}},
+	}
+	out, err := Markdown(tree, "p", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "(javascript") {
+		t.Errorf("output has a live javascript: link:\n%s", out)
+	}
+	if !strings.Contains(out, "[site](https://example.com)") {
+		t.Errorf("output lost the https link:\n%s", out)
+	}
+	if !strings.Contains(out, "click") {
+		t.Errorf("unsafe link text missing:\n%s", out)
+	}
+}
@@ -0,0 +1,546 @@
+package render
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Math renders a TeX equation as MathML, which browsers display without
+// scripts or fonts from the network. It covers the TeX subset Notion's
+// equation editor is used with (fractions, roots, scripts, big operators,
+// accents, fonts, delimiters and matrix environments); unknown commands are
+// kept as text. The TeX source is attached as an annotation, as KaTeX does.
+func Math(tex string, display bool) string {
+	p := &texParser{src: tex, display: display}
+	body := p.list()
+	for p.peek() != "" {
+		// Stray closing tokens at the top level.
+		p.take()
+		body = append(body, p.list()...)
+	}
+	attr := ""
+	if display {
+		attr = ` display="block"`
+	}
+	return `<math` + attr + `><semantics>` + mrow(body) + `<annotation encoding="application/x-tex">` +
+		html.EscapeString(tex) + `</annotation></semantics></math>`
+}
+
+type mathNode struct {
+	ml string
+	// limits places scripts above and below instead of to the right.
+	limits bool
+}
+
+type texParser struct {
+	src     string
+	pos     int
+	display bool
+	// font is the \mathbb, \mathbf, ... variant applied to letters.
+	font string
+}
+
+func (p *texParser) skipSpace() {
+	for p.pos < len(p.src) {
+		r, n := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += n
+	}
+}
+
+// token returns the next token at pos and its length: a command, a run of
+// digits or a single character.
+func (p *texParser) token() (string, int) {
+	p.skipSpace()
+	s := p.src[p.pos:]
+	if s == "" {
+		return "", 0
+	}
+	if s[0] == '\\' {
+		if len(s) == 1 {
+			return `\`, 1
+		}
+		n := 1
+		for n < len(s) && isASCIILetter(s[n]) {
+			n++
+		}
+		if n == 1 {
+			_, size := utf8.DecodeRuneInString(s[1:])
+			n += size
+		}
+		return s[:n], n
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		n := 1
+		for n < len(s) && (s[n] >= '0' && s[n] <= '9' || s[n] == '.' && n+1 < len(s) && s[n+1] >= '0' && s[n+1] <= '9') {
+			n++
+		}
+		return s[:n], n
+	}
+	_, n := utf8.DecodeRuneInString(s)
+	return s[:n], n
+}
+
+func (p *texParser) peek() string {
+	tok, _ := p.token()
+	return tok
+}
+
+func (p *texParser) take() string {
+	tok, n := p.token()
+	p.pos += n
+	return tok
+}
+
+// list parses nodes up to the end of input or one of the stop tokens, which
+// is left unread.
+func (p *texParser) list(stops ...string) []mathNode {
+	out := make([]mathNode, 0)
+	for {
+		tok := p.peek()
+		if tok == "" || contains(stops, tok) {
+			return out
+		}
+		switch tok {
+		case "^", "_":
+			p.take()
+			base := mathNode{ml: "<mrow></mrow>"}
+			if len(out) > 0 {
+				base = out[len(out)-1]
+				out = out[:len(out)-1]
+			}
+			out = append(out, p.scripts(base, tok))
+		case "}", `\right`, `\end`, "&", `\\`:
+			// Unbalanced outside of their group; drop them.
+			p.take()
+			if tok == `\right` {
+				p.take()
+			} else if tok == `\end` {
+				p.rawGroup()
+			}
+		default:
+			out = append(out, p.atom(p.take()))
+		}
+	}
+}
+
+// scripts attaches the script starting with op (and a following script of
+// the other kind) to base.
+func (p *texParser) scripts(base mathNode, op string) mathNode {
+	var sub, sup string
+	set := func(op string, v string) {
+		if op == "_" {
+			sub = v
+		} else {
+			sup = v
+		}
+	}
+	set(op, p.arg())
+	if next := p.peek(); (next == "^" || next == "_") && next != op {
+		p.take()
+		set(next, p.arg())
+	}
+	tags := [3]string{"msub", "msup", "msubsup"}
+	if base.limits {
+		tags = [3]string{"munder", "mover", "munderover"}
+	}
+	switch {
+	case sub != "" && sup != "":
+		return mathNode{ml: "<" + tags[2] + ">" + base.ml + sub + sup + "</" + tags[2] + ">"}
+	case sub != "":
+		return mathNode{ml: "<" + tags[0] + ">" + base.ml + sub + "</" + tags[0] + ">"}
+	}
+	return mathNode{ml: "<" + tags[1] + ">" + base.ml + sup + "</" + tags[1] + ">"}
+}
+
+// arg parses one argument: a braced group or a single token.
+func (p *texParser) arg() string {
+	tok := p.take()
+	switch tok {
+	case "":
+		return "<mrow></mrow>"
+	case "{":
+		return p.group()
+	}
+	return p.atom(tok).ml
+}
+
+// group parses up to the closing brace of a group whose "{" was read.
+func (p *texParser) group() string {
+	nodes := p.list("}")
+	p.take()
+	return mrow(nodes)
+}
+
+// rawGroup returns the text of a braced group verbatim, for \text and
+// environment names.
+func (p *texParser) rawGroup() string {
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+		tok := p.take()
+		return tok
+	}
+	depth := 0
+	for i := p.pos; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				s := p.src[p.pos+1 : i]
+				p.pos = i + 1
+				return s
+			}
+		}
+	}
+	s := p.src[p.pos+1:]
+	p.pos = len(p.src)
+	return s
+}
+
+// optional returns the [..] argument of a command, or "" without one.
+func (p *texParser) optional() string {
+	if p.peek() != "[" {
+		return ""
+	}
+	p.take()
+	nodes := p.list("]")
+	p.take()
+	return mrow(nodes)
+}
+
+func (p *texParser) atom(tok string) mathNode {
+	switch {
+	case tok == "{":
+		return mathNode{ml: p.group()}
+	case len(tok) == 1 && isASCIILetter(tok[0]):
+		return mathNode{ml: p.letter(tok)}
+	case tok[0] >= '0' && tok[0] <= '9':
+		return mathNode{ml: "<mn>" + p.styled(tok) + "</mn>"}
+	case tok == "'":
+		return mathNode{ml: "<mo>′</mo>"}
+	case tok == "~":
+		return mathNode{ml: `<mspace width="0.3333em"></mspace>`}
+	case tok[0] != '\\':
+		return mathNode{ml: "<mo>" + html.EscapeString(tok) + "</mo>"}
+	}
+	return p.command(tok)
+}
+
+func (p *texParser) letter(s string) string {
+	if p.font != "" {
+		return `<mi mathvariant="normal">` + p.styled(s) + "</mi>"
+	}
+	return "<mi>" + html.EscapeString(s) + "</mi>"
+}
+
+// styled maps ASCII letters and digits of s to the Unicode mathematical
+// alphanumerics of the current font.
+func (p *texParser) styled(s string) string {
+	if p.font == "" || p.font == "rm" {
+		return html.EscapeString(s)
+	}
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteRune(mathAlphanumeric(p.font, r))
+	}
+	return html.EscapeString(b.String())
+}
+
+func (p *texParser) command(cmd string) mathNode {
+	name := cmd[1:]
+	if s, ok := texIdentifiers[name]; ok {
+		if p.font == "bf" || p.font == "bm" {
+			return mathNode{ml: `<mi mathvariant="bold">` + s + "</mi>"}
+		}
+		return mathNode{ml: "<mi>" + s + "</mi>"}
+	}
+	if s, ok := texOperators[name]; ok {
+		return mathNode{ml: "<mo>" + html.EscapeString(s) + "</mo>"}
+	}
+	if s, ok := texBigOperators[name]; ok {
+		return mathNode{ml: `<mo largeop="true" movablelimits="true">` + s + "</mo>", limits: p.display && !strings.HasPrefix(name, "i") && name != "oint"}
+	}
+	if texFunctions[name] {
+		return mathNode{ml: "<mi>" + name + "</mi>"}
+	}
+	if texLimitFunctions[name] {
+		return mathNode{ml: "<mi>" + name + "</mi>", limits: p.display}
+	}
+	if width, ok := texSpaces[name]; ok {
+		return mathNode{ml: `<mspace width="` + width + `"></mspace>`}
+	}
+	if font, ok := texFonts[name]; ok {
+		outer := p.font
+		p.font = font
+		ml := p.arg()
+		p.font = outer
+		return mathNode{ml: ml}
+	}
+	if accent, ok := texAccents[name]; ok {
+		return mathNode{ml: `<mover accent="true">` + p.arg() + "<mo>" + accent + "</mo></mover>"}
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac":
+		num := p.arg()
+		return mathNode{ml: "<mfrac>" + num + p.arg() + "</mfrac>"}
+	case "binom", "dbinom", "tbinom":
+		top := p.arg()
+		return mathNode{ml: `<mrow><mo>(</mo><mfrac linethickness="0">` + top + p.arg() + "</mfrac><mo>)</mo></mrow>"}
+	case "sqrt":
+		if index := p.optional(); index != "" {
+			return mathNode{ml: "<mroot>" + p.arg() + index + "</mroot>"}
+		}
+		return mathNode{ml: "<msqrt>" + p.arg() + "</msqrt>"}
+	case "text", "textrm", "textit", "textbf", "textsf", "texttt", "mbox", "hbox":
+		return mathNode{ml: "<mtext>" + html.EscapeString(texTextUnescape(p.rawGroup())) + "</mtext>"}
+	case "operatorname", "mathrm":
+		text := strings.ReplaceAll(p.rawGroup(), " ", "")
+		if name == "mathrm" && utf8.RuneCountInString(text) == 1 {
+			return mathNode{ml: `<mi mathvariant="normal">` + html.EscapeString(text) + "</mi>"}
+		}
+		return mathNode{ml: "<mi>" + html.EscapeString(text) + "</mi>"}
+	case "underline":
+		return mathNode{ml: `<munder accentunder="true">` + p.arg() + "<mo>_</mo></munder>"}
+	case "overbrace":
+		return mathNode{ml: `<mover accent="true">` + p.arg() + `<mo stretchy="true">⏞</mo></mover>`, limits: true}
+	case "underbrace":
+		return mathNode{ml: `<munder accentunder="true">` + p.arg() + `<mo stretchy="true">⏟</mo></munder>`, limits: true}
+	case "left":
+		open := p.delimiter()
+		inner := p.list(`\right`)
+		p.take()
+		close := p.delimiter()
+		return mathNode{ml: "<mrow>" + fence(open) + mrow(inner) + fence(close) + "</mrow>"}
+	case "middle":
+		return mathNode{ml: fence(p.delimiter())}
+	case "big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr", "biggl", "biggr", "Biggl", "Biggr":
+		return mathNode{ml: "<mo>" + html.EscapeString(p.delimiter()) + "</mo>"}
+	case "begin":
+		return mathNode{ml: p.environment(p.rawGroup())}
+	case "label", "tag", "color":
+		p.rawGroup()
+		return mathNode{}
+	case "displaystyle", "textstyle", "scriptstyle", "limits", "nolimits", "nonumber", "notag":
+		return mathNode{}
+	}
+	if len(name) == 1 && !isASCIILetter(name[0]) {
+		// \{ \} \% \$ \# \& \_ and friends.
+		return mathNode{ml: "<mo>" + html.EscapeString(name) + "</mo>"}
+	}
+	return mathNode{ml: "<mtext>" + html.EscapeString(cmd) + "</mtext>"}
+}
+
+// delimiter reads the delimiter after \left, \right, \big, ...; "." is none.
+func (p *texParser) delimiter() string {
+	tok := p.take()
+	if tok == "." {
+		return ""
+	}
+	if strings.HasPrefix(tok, `\`) {
+		if s, ok := texOperators[tok[1:]]; ok {
+			return s
+		}
+		return tok[1:]
+	}
+	return tok
+}
+
+func fence(delim string) string {
+	if delim == "" {
+		return ""
+	}
+	return `<mo fence="true" stretchy="true">` + html.EscapeString(delim) + "</mo>"
+}
+
+// environment parses the body of \begin{name} up to \end{name} as a table.
+func (p *texParser) environment(name string) string {
+	if name == "array" || name == "alignedat" {
+		p.rawGroup()
+	}
+	rows := make([][]string, 0)
+	row := make([]string, 0)
+	for {
+		row = append(row, mrow(p.list("&", `\\`, `\end`)))
+		switch p.take() {
+		case "&":
+			continue
+		case `\\`:
+			p.optional()
+			rows = append(rows, row)
+			row = make([]string, 0)
+			continue
+		case `\end`:
+			p.rawGroup()
+		}
+		break
+	}
+	if len(row) > 1 || row[0] != "<mrow></mrow>" {
+		rows = append(rows, row)
+	}
+
+	align := ""
+	switch strings.TrimSuffix(name, "*") {
+	case "aligned", "align", "split", "alignedat", "flalign":
+		align = ` columnalign="right left right left right left"`
+	case "cases", "array":
+		align = ` columnalign="left left"`
+	}
+	var b strings.Builder
+	b.WriteString("<mtable" + align + ">")
+	for _, r := range rows {
+		b.WriteString("<mtr>")
+		for _, cell := range r {
+			b.WriteString("<mtd>" + cell + "</mtd>")
+		}
+		b.WriteString("</mtr>")
+	}
+	b.WriteString("</mtable>")
+	table := b.String()
+
+	fences := map[string][2]string{
+		"pmatrix": {"(", ")"},
+		"bmatrix": {"[", "]"},
+		"Bmatrix": {"{", "}"},
+		"vmatrix": {"|", "|"},
+		"Vmatrix": {"‖", "‖"},
+		"cases":   {"{", ""},
+	}
+	if f, ok := fences[name]; ok {
+		return "<mrow>" + fence(f[0]) + table + fence(f[1]) + "</mrow>"
+	}
+	return table
+}
+
+func mrow(nodes []mathNode) string {
+	if len(nodes) == 1 {
+		return nodes[0].ml
+	}
+	var b strings.Builder
+	b.WriteString("<mrow>")
+	for _, n := range nodes {
+		b.WriteString(n.ml)
+	}
+	b.WriteString("</mrow>")
+	return b.String()
+}
+
+func texTextUnescape(s string) string {
+	r := strings.NewReplacer(`\{`, "{", `\}`, "}", `\%`, "%", `\$`, "$", `\&`, "&", `\#`, "#", `\_`, "_", `\ `, " ", "~", " ")
+	return r.Replace(s)
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mathAlphanumeric returns r in a Unicode mathematical alphanumeric font:
+// "bb" (double-struck), "cal" (script), "frak", "bf", "bm" (bold italic),
+// "it", "sf" and "tt".
+func mathAlphanumeric(font string, r rune) rune {
+	if s, ok := mathLetterHoles[font+string(r)]; ok {
+		return s
+	}
+	upper := map[string]rune{"bf": 0x1D400, "it": 0x1D434, "bm": 0x1D468, "cal": 0x1D49C, "frak": 0x1D504, "bb": 0x1D538, "sf": 0x1D5A0, "tt": 0x1D670}
+	digits := map[string]rune{"bf": 0x1D7CE, "bm": 0x1D7CE, "bb": 0x1D7D8, "sf": 0x1D7E2, "tt": 0x1D7F6}
+	switch {
+	case r >= 'A' && r <= 'Z' && upper[font] != 0:
+		return upper[font] + r - 'A'
+	case r >= 'a' && r <= 'z' && upper[font] != 0:
+		return upper[font] + 26 + r - 'a'
+	case r >= '0' && r <= '9' && digits[font] != 0:
+		return digits[font] + r - '0'
+	}
+	return r
+}
+
+// mathLetterHoles are the letters encoded outside the mathematical
+// alphanumeric block, in Letterlike Symbols.
+var mathLetterHoles = map[string]rune{
+	"ith":  'ℎ',
+	"calB": 'ℬ', "calE": 'ℰ', "calF": 'ℱ', "calH": 'ℋ', "calI": 'ℐ', "calL": 'ℒ', "calM": 'ℳ', "calR": 'ℛ',
+	"cale": 'ℯ', "calg": 'ℊ', "calo": 'ℴ',
+	"frakC": 'ℭ', "frakH": 'ℌ', "frakI": 'ℑ', "frakR": 'ℜ', "frakZ": 'ℨ',
+	"bbC": 'ℂ', "bbH": 'ℍ', "bbN": 'ℕ', "bbP": 'ℙ', "bbQ": 'ℚ', "bbR": 'ℝ', "bbZ": 'ℤ',
+}
+
+var texFonts = map[string]string{
+	"mathbb": "bb", "mathcal": "cal", "mathscr": "cal", "mathfrak": "frak",
+	"mathbf": "bf", "boldsymbol": "bm", "bm": "bm", "mathit": "it",
+	"mathsf": "sf", "mathtt": "tt", "mathnormal": "",
+}
+
+var texIdentifiers = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ",
+	"varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ",
+	"varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+	"infty": "∞", "partial": "∂", "nabla": "∇", "hbar": "ℏ", "ell": "ℓ", "emptyset": "∅",
+	"varnothing": "∅", "aleph": "ℵ", "Re": "ℜ", "Im": "ℑ", "wp": "℘", "imath": "ı", "jmath": "ȷ",
+}
+
+var texOperators = map[string]string{
+	"times": "×", "cdot": "⋅", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗", "star": "⋆",
+	"circ": "∘", "bullet": "∙", "oplus": "⊕", "ominus": "⊖", "otimes": "⊗", "odot": "⊙",
+	"cup": "∪", "cap": "∩", "setminus": "∖", "wedge": "∧", "land": "∧", "vee": "∨", "lor": "∨",
+	"neg": "¬", "lnot": "¬",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "ll": "≪", "gg": "≫",
+	"approx": "≈", "equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "supset": "⊃", "subseteq": "⊆",
+	"supseteq": "⊇", "perp": "⊥", "parallel": "∥", "mid": "∣", "models": "⊨", "vdash": "⊢",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "implies": "⟹", "iff": "⟺",
+	"mapsto": "↦", "longrightarrow": "⟶", "longleftarrow": "⟵", "uparrow": "↑", "downarrow": "↓",
+	"forall": "∀", "exists": "∃", "nexists": "∄",
+	"ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"langle": "⟨", "rangle": "⟩", "lceil": "⌈", "rceil": "⌉", "lfloor": "⌊", "rfloor": "⌋",
+	"lbrace": "{", "rbrace": "}", "vert": "|", "Vert": "‖", "|": "‖", "backslash": "∖",
+	"prime": "′", "angle": "∠", "triangle": "△", "degree": "°",
+}
+
+var texBigOperators = map[string]string{
+	"sum": "∑", "prod": "∏", "coprod": "∐", "int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+	"bigcup": "⋃", "bigcap": "⋂", "bigoplus": "⨁", "bigotimes": "⨂", "bigvee": "⋁", "bigwedge": "⋀",
+}
+
+var texFunctions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+	"arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true, "tanh": true,
+	"log": true, "ln": true, "lg": true, "exp": true, "dim": true, "ker": true, "deg": true,
+	"arg": true, "hom": true,
+}
+
+var texLimitFunctions = map[string]bool{
+	"lim": true, "limsup": true, "liminf": true, "max": true, "min": true, "sup": true,
+	"inf": true, "det": true, "gcd": true, "Pr": true, "argmax": true, "argmin": true,
+}
+
+var texSpaces = map[string]string{
+	",": "0.1667em", ":": "0.2222em", ">": "0.2222em", ";": "0.2778em", "!": "-0.1667em",
+	" ": "0.3333em", "quad": "1em", "qquad": "2em",
+}
+
+var texAccents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "¯", "overline": "‾", "vec": "→", "overrightarrow": "→",
+	"tilde": "~", "widetilde": "~", "dot": "˙", "ddot": "¨", "check": "ˇ", "breve": "˘", "acute": "´", "grave": "`",
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMath(t *testing.T) {
+	tests := []struct {
+		tex     string
+		display bool
+		want    string
+	}{
+		{`x^2`, false, `<msup><mi>x</mi><mn>2</mn></msup>`},
+		{`a_{i,j}^n`, false, `<msubsup><mi>a</mi><mrow><mi>i</mi><mo>,</mo><mi>j</mi></mrow><mi>n</mi></msubsup>`},
+		{`\frac{1}{2}`, false, `<mfrac><mn>1</mn><mn>2</mn></mfrac>`},
+		{`\sqrt[3]{x}`, false, `<mroot><mi>x</mi><mn>3</mn></mroot>`},
+		{`\alpha \leq 3.14`, false, `<mrow><mi>α</mi><mo>≤</mo><mn>3.14</mn></mrow>`},
+		{`\sum_{i=1}^n i`, true, `<munderover><mo largeop="true" movablelimits="true">∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover>`},
+		{`\sum_{i=1}^n i`, false, `<msubsup><mo largeop="true" movablelimits="true">∑</mo>`},
+		{`\int_0^1`, true, `<msubsup><mo largeop="true" movablelimits="true">∫</mo><mn>0</mn><mn>1</mn></msubsup>`},
+		{`\mathbb{R}^n`, false, `<msup><mi mathvariant="normal">ℝ</mi><mi>n</mi></msup>`},
+		{`\mathbf{v}`, false, `<mi mathvariant="normal">𝐯</mi>`},
+		{`\text{if } x<0`, false, `<mtext>if </mtext><mi>x</mi><mo>&lt;</mo><mn>0</mn>`},
+		{`\left( \frac{a}{b} \right)`, false, `<mrow><mo fence="true" stretchy="true">(</mo><mfrac><mi>a</mi><mi>b</mi></mfrac><mo fence="true" stretchy="true">)</mo></mrow>`},
+		{`\begin{pmatrix} 1 & 0 \\ 0 & 1 \end{pmatrix}`, true, `<mtable><mtr><mtd><mn>1</mn></mtd><mtd><mn>0</mn></mtd></mtr><mtr><mtd><mn>0</mn></mtd><mtd><mn>1</mn></mtd></mtr></mtable>`},
+		{`\hat{x}`, false, `<mover accent="true"><mi>x</mi><mo>^</mo></mover>`},
+		{`\unknown{x}`, false, `<mtext>\unknown</mtext><mi>x</mi>`},
+	}
+	for _, tt := range tests {
+		got := Math(tt.tex, tt.display)
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("Math(%q, %v) = %s\nwant it to contain %s", tt.tex, tt.display, got, tt.want)
+		}
+	}
+}
+
+func TestMathDocument(t *testing.T) {
+	got := Math(`a<b`, true)
+	want := `<math display="block"><semantics><mrow><mi>a</mi><mo>&lt;</mo><mi>b</mi></mrow>` +
+		`<annotation encoding="application/x-tex">a&lt;b</annotation></semantics></math>`
+	if got != want {
+		t.Errorf("Math = %s, want %s", got, want)
+	}
+}
+
+func TestMathMalformed(t *testing.T) {
+	for _, tex := range []string{`}`, `{`, `x^`, `\frac{1}`, `\left(`, `\right)`, `\begin{matrix} 1 &`, `\end{x}`, `\`, `\sqrt[`, `a & b \\ c`} {
+		got := Math(tex, false)
+		if !strings.HasPrefix(got, "<math>") || !strings.HasSuffix(got, "</math>") {
+			t.Errorf("Math(%q) = %s", tex, got)
+		}
+	}
+}
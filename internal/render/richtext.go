@@ -0,0 +1,95 @@
+package render
+
+import (
+	"html"
+	"strings"
+)
+
+// colorClass turns a Notion color ("red", "blue_background") into a CSS
+// class name.
+func colorClass(color string) string {
+	if color == "" || color == "default" {
+		return ""
+	}
+	return "color-" + strings.ReplaceAll(color, "_", "-")
+}
+
+// richText renders Notion rich text segments as inline HTML.
+func (r *renderer) richText(v any) string {
+	segments, _ := v.([]any)
+	var b strings.Builder
+	for _, seg := range segments {
+		parts, _ := seg.([]any)
+		if len(parts) == 0 {
+			continue
+		}
+		text, _ := parts[0].(string)
+		var decorations []any
+		if len(parts) > 1 {
+			decorations, _ = parts[1].([]any)
+		}
+		b.WriteString(r.segment(text, decorations))
+	}
+	return b.String()
+}
+
+func (r *renderer) segment(text string, decorations []any) string {
+	out := escapeText(text)
+	var link string
+	wrappers := make([][2]string, 0)
+	for _, d := range decorations {
+		dec, _ := d.([]any)
+		if len(dec) == 0 {
+			continue
+		}
+		tag, _ := dec[0].(string)
+		arg := ""
+		if len(dec) > 1 {
+			arg, _ = dec[1].(string)
+		}
+		switch tag {
+		case "b":
+			wrappers = append(wrappers, [2]string{"<strong>", "</strong>"})
+		case "i":
+			wrappers = append(wrappers, [2]string{"<em>", "</em>"})
+		case "s":
+			wrappers = append(wrappers, [2]string{"<s>", "</s>"})
+		case "_":
+			wrappers = append(wrappers, [2]string{"<u>", "</u>"})
+		case "c":
+			wrappers = append(wrappers, [2]string{"<code>", "</code>"})
+		case "h":
+			if class := colorClass(arg); class != "" {
+				wrappers = append(wrappers, [2]string{`<span class="` + class + `">`, "</span>"})
+			}
+		case "a":
+			link = arg
+		case "e":
+			out = `<span class="math math-inline">` + Math(arg, false) + `</span>`
+		case "p":
+			title := r.pageTitle(arg)
+			out = `<a class="page-mention" href="` + html.EscapeString(r.pageHref(arg)) + `">` + html.EscapeString(title) + `</a>`
+		case "u":
+			out = `<span class="user-mention">@` + html.EscapeString(r.userName(arg)) + `</span>`
+		case "d":
+			date, _ := dec[1].(map[string]any)
+			start, _ := date["start_date"].(string)
+			label := start
+			if end, _ := date["end_date"].(string); end != "" {
+				label += " → " + end
+			}
+			out = `<time datetime="` + html.EscapeString(start) + `">` + html.EscapeString(label) + `</time>`
+		}
+	}
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		out = wrappers[i][0] + out + wrappers[i][1]
+	}
+	if href := r.linkHref(link); href != "" {
+		out = `<a href="` + html.EscapeString(href) + `">` + out + `</a>`
+	}
+	return out
+}
+
+func escapeText(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+}
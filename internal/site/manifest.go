@@ -0,0 +1,69 @@
+package site
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestName is the file in the output directory that records the last
+// build. Both generators ignore dotfiles in their content directories.
+const ManifestName = ".nocli-site.json"
+
+// Manifest records which file each page was written to and the fingerprint
+// it was built from, so the next build can skip unchanged pages and remove
+// pages that were moved or deleted. Records holds the raw records the build
+// used, by table and ID; the next build only fetches those whose version
+// changed.
+type Manifest struct {
+	Generator string                               `json:"generator"`
+	RootID    string                               `json:"root_id"`
+	BuiltAt   string                               `json:"built_at,omitempty"`
+	Pages     map[string]ManifestPage              `json:"pages"`
+	Records   map[string]map[string]map[string]any `json:"records,omitempty"`
+}
+
+type ManifestPage struct {
+	Path        string `json:"path"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// LoadManifest reads the manifest of dir. A missing manifest is not an
+// error; ok is false then.
+func LoadManifest(dir string) (Manifest, bool, error) {
+	path := filepath.Join(dir, ManifestName)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Manifest{}, false, nil
+	}
+	if err != nil {
+		return Manifest{}, false, fmt.Errorf("read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return Manifest{}, false, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	return m, true, nil
+}
+
+// SaveManifest atomically rewrites the manifest of dir.
+func SaveManifest(dir string, m Manifest) error {
+	m.BuiltAt = time.Now().UTC().Format(time.RFC3339)
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	path := filepath.Join(dir, ManifestName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit manifest: %w", err)
+	}
+	return nil
+}
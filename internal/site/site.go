@@ -0,0 +1,290 @@
+// Package site lays out a page tree as the content directory of a Hugo or
+// Jekyll site.
+package site
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jodok/nocli/internal/archive"
+	"github.com/jodok/nocli/internal/notionclient"
+	"github.com/jodok/nocli/internal/pagegraph"
+)
+
+const (
+	Hugo   = "hugo"
+	Jekyll = "jekyll"
+)
+
+// Page is one content file of the site.
+type Page struct {
+	ID     string
+	Parent string
+	Title  string
+	// Row is set for database rows.
+	Row bool
+	// Path is the content file relative to the output directory, with
+	// forward slashes.
+	Path string
+	// URL is the site path the generator publishes the file at, assuming
+	// the output directory is the content root.
+	URL string
+	// Weight is the 1-based position among the page's siblings.
+	Weight int
+	// Blocks are the blocks rendered on the page, the page itself first.
+	Blocks []string
+}
+
+// Plan assigns a content file to every page below rootID. Pages with
+// subpages become directories with an index file, so the site mirrors the
+// page hierarchy. The root page is first, followed by its descendants in
+// document order.
+func Plan(tree *notionclient.Tree, rootID string, generator string) []*Page {
+	byID := map[string]*Page{}
+	order := make([]*Page, 0)
+	pagegraph.Walk(tree, func(owner string, id string, block map[string]any, row bool) {
+		if owner == id {
+			p := &Page{ID: id, Title: pageTitle(tree, block), Row: row}
+			byID[id] = p
+			order = append(order, p)
+		}
+		if p := byID[owner]; p != nil {
+			p.Blocks = append(p.Blocks, id)
+		}
+	})
+	root := byID[rootID]
+	if root == nil {
+		return nil
+	}
+
+	children := map[string][]*Page{}
+	for _, p := range order {
+		if p == root {
+			continue
+		}
+		p.Parent = pagegraph.ParentPage(tree, p.ID)
+		if byID[p.Parent] == nil {
+			p.Parent = rootID
+		}
+		children[p.Parent] = append(children[p.Parent], p)
+	}
+
+	index := "_index.md"
+	if generator == Jekyll {
+		index = "index.md"
+	}
+	var assign func(p *Page, dir string)
+	assign = func(p *Page, dir string) {
+		used := map[string]bool{}
+		for i, child := range children[p.ID] {
+			child.Weight = i + 1
+			name := notionclient.Slug(child.Title)
+			if name == "" || used[name] || name == "index" || name == "_index" {
+				name = strings.Trim(name+"-"+strings.ReplaceAll(child.ID, "-", "")[:8], "-")
+			}
+			used[name] = true
+			if len(children[child.ID]) > 0 {
+				child.Path = path.Join(dir, name, index)
+				assign(child, path.Join(dir, name))
+			} else {
+				child.Path = path.Join(dir, name+".md")
+			}
+			child.URL = publishedURL(child.Path, generator)
+		}
+	}
+	root.Path = index
+	root.URL = "/"
+	assign(root, "")
+
+	out := make([]*Page, 0, len(order))
+	var collect func(p *Page)
+	collect = func(p *Page) {
+		out = append(out, p)
+		for _, child := range children[p.ID] {
+			collect(child)
+		}
+	}
+	collect(root)
+	return out
+}
+
+// publishedURL maps a content file to the URL the generator publishes it
+// at with default settings: pretty URLs for Hugo, .html files for Jekyll.
+func publishedURL(file string, generator string) string {
+	dir, name := path.Split(file)
+	switch {
+	case name == "_index.md" || name == "index.md":
+		return "/" + dir
+	case generator == Jekyll:
+		return "/" + strings.TrimSuffix(file, ".md") + ".html"
+	}
+	return "/" + strings.TrimSuffix(file, ".md") + "/"
+}
+
+// RelativeURL returns a link from the page published at from to the site
+// path to.
+func RelativeURL(from string, to string) string {
+	base := strings.Split(strings.Trim(from[:strings.LastIndex(from, "/")+1], "/"), "/")
+	if base[0] == "" {
+		base = nil
+	}
+	target := strings.Split(strings.TrimPrefix(to, "/"), "/")
+	i := 0
+	for i < len(base) && i < len(target)-1 && base[i] == target[i] {
+		i++
+	}
+	rel := strings.Repeat("../", len(base)-i) + strings.Join(target[i:], "/")
+	if rel == "" {
+		return "./"
+	}
+	return rel
+}
+
+func pageTitle(tree *notionclient.Tree, block map[string]any) string {
+	if title := notionclient.PageTitle(tree.Records, block); title != "" {
+		return title
+	}
+	return "Untitled"
+}
+
+// Fingerprint hashes the record versions a page's output depends on: its
+// blocks, the databases shown on it with their rows, and the pages it links
+// to with their URLs. Linked pages outside the tree are versioned by linked,
+// and the users named on the page by the names userName resolves them to.
+// extra adds other inputs from outside the tree, such as front matter
+// settings.
+func Fingerprint(tree *notionclient.Tree, p *Page, urls map[string]string, linked map[string]map[string]any, userName func(id string) string, extra ...string) string {
+	h := sha256.New()
+	users := make([]string, 0)
+	add := func(kind string, table string, id string) {
+		record := tree.Records[table][id]
+		if record == nil && table == "block" {
+			record = linked[id]
+		}
+		_, _ = fmt.Fprintf(h, "%s %s %d %s\n", kind, id, archive.RecordVersion(record), urls[id])
+		if kind == "block" || kind == "row" {
+			users = append(users, notionclient.CollectUserIDs(record)...)
+		}
+	}
+	for _, s := range extra {
+		_, _ = fmt.Fprintf(h, "extra %q\n", s)
+	}
+	_, _ = fmt.Fprintf(h, "path %s\n", p.Path)
+
+	rows := notionclient.RowsByCollection(tree.Records)
+	for _, id := range p.Blocks {
+		block := tree.Block(id)
+		add("block", "block", id)
+		if pt, _ := block["parent_table"].(string); pt == "collection" {
+			cid, _ := block["parent_id"].(string)
+			add("collection", "collection", cid)
+		}
+		if cid := notionclient.CollectionIDForBlock(block, tree.Records["collection_view"]); cid != "" {
+			add("collection", "collection", cid)
+			viewIDs, _ := block["view_ids"].([]any)
+			for _, v := range viewIDs {
+				if viewID, ok := v.(string); ok {
+					add("view", "collection_view", viewID)
+				}
+			}
+			for _, rowID := range rows[cid] {
+				add("row", "block", rowID)
+			}
+		}
+		for _, child := range notionclient.ContentIDs(block) {
+			if notionclient.IsPageBlock(tree.Block(child)) {
+				add("child", "block", child)
+			}
+		}
+		for _, ref := range pagegraph.BlockReferences(tree.Records, block) {
+			add("ref", "block", ref.Target)
+		}
+	}
+	sort.Strings(users)
+	for i, id := range users {
+		if i == 0 || users[i-1] != id {
+			_, _ = fmt.Fprintf(h, "user %s %q\n", id, userName(id))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var (
+	liquidPattern    = regexp.MustCompile(`\{\{|\{%`)
+	endrawPattern    = regexp.MustCompile(`\{%-?\s*endraw\s*-?%\}`)
+	shortcodePattern = regexp.MustCompile(`(?s)\{\{([<%])(.*?)([>%])\}\}`)
+)
+
+// EscapeTemplates keeps page text that looks like template syntax from being
+// run by the generator: Jekyll bodies containing Liquid tags or output are
+// wrapped in {% raw %}, and Hugo shortcode calls are turned into the
+// {{</* */>}} form Hugo prints literally.
+func EscapeTemplates(body string, generator string) string {
+	if generator == Jekyll {
+		if !liquidPattern.MatchString(body) {
+			return body
+		}
+		body = endrawPattern.ReplaceAllStringFunc(body, func(tag string) string {
+			return "{% endraw %}{{ \"" + tag + "\" }}{% raw %}"
+		})
+		return "{% raw %}" + body + "{% endraw %}"
+	}
+	return shortcodePattern.ReplaceAllString(body, "{{$1/*$2*/$3}}")
+}
+
+// FrontMatter is the metadata written at the top of each content file.
+type FrontMatter struct {
+	Title     string
+	Date      string
+	Lastmod   string
+	Authors   []string
+	Tags      []string
+	Weight    int
+	NotionID  string
+	NotionURL string
+}
+
+// Render returns the front matter as a YAML block, with the field names the
+// generator and its common themes expect.
+func (fm FrontMatter) Render(generator string) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	field := func(key string, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", key, strconv.Quote(value))
+		}
+	}
+	list := func(key string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s:\n", key)
+		for _, v := range values {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(v))
+		}
+	}
+	field("title", fm.Title)
+	field("date", fm.Date)
+	if generator == Jekyll {
+		field("last_modified_at", fm.Lastmod)
+	} else {
+		field("lastmod", fm.Lastmod)
+	}
+	list("authors", fm.Authors)
+	tags := append([]string(nil), fm.Tags...)
+	sort.Strings(tags)
+	list("tags", tags)
+	if generator == Hugo && fm.Weight > 0 {
+		fmt.Fprintf(&b, "weight: %d\n", fm.Weight)
+	}
+	field("notion_id", fm.NotionID)
+	field("notion_url", fm.NotionURL)
+	b.WriteString("---\n")
+	return b.String()
+}
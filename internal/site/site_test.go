@@ -0,0 +1,105 @@
+package site
+
+import (
+	"testing"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+func TestRelativeURL(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want string
+	}{
+		{"/", "/", "./"},
+		{"/", "/a/", "a/"},
+		{"/", "/img/x.png", "img/x.png"},
+		{"/a/", "/a/", "./"},
+		{"/a/", "/b/", "../b/"},
+		{"/a/", "/a/x.png", "x.png"},
+		{"/a/b/", "/a/c/", "../c/"},
+		{"/a/b/", "/", "../../"},
+		{"/a/b/", "/a/b/c/", "c/"},
+		{"/a/b.html", "/a/c.html", "c.html"},
+		{"/a/b.html", "/", "../"},
+		{"/a.html", "/b/c.html", "b/c.html"},
+	}
+	for _, tt := range tests {
+		if got := RelativeURL(tt.from, tt.to); got != tt.want {
+			t.Errorf("RelativeURL(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPublishedURL(t *testing.T) {
+	tests := []struct {
+		file      string
+		generator string
+		want      string
+	}{
+		{"_index.md", Hugo, "/"},
+		{"a/_index.md", Hugo, "/a/"},
+		{"a/b.md", Hugo, "/a/b/"},
+		{"index.md", Jekyll, "/"},
+		{"a/index.md", Jekyll, "/a/"},
+		{"a/b.md", Jekyll, "/a/b.html"},
+	}
+	for _, tt := range tests {
+		if got := publishedURL(tt.file, tt.generator); got != tt.want {
+			t.Errorf("publishedURL(%q, %q) = %q, want %q", tt.file, tt.generator, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	tree := &notionclient.Tree{
+		Roots: []string{"p"},
+		Records: map[string]map[string]map[string]any{"block": {
+			"p": {"type": "page", "version": 1.0, "created_by_id": "11111111-1111-1111-1111-111111111111", "content": []any{"t"}},
+			"t": {"type": "text", "version": 1.0, "properties": map[string]any{"title": []any{
+				[]any{"‣", []any{[]any{"p", "ext"}}},
+			}}},
+		}},
+	}
+	p := &Page{ID: "p", Path: "_index.md", Blocks: []string{"p", "t"}}
+	name := "Ada"
+	userName := func(string) string { return name }
+	linked := map[string]map[string]any{"ext": {"version": 1.0}}
+
+	base := Fingerprint(tree, p, nil, linked, userName)
+	if again := Fingerprint(tree, p, nil, linked, userName); again != base {
+		t.Fatalf("fingerprint is not stable: %s != %s", again, base)
+	}
+	linked["ext"]["version"] = 2.0
+	bumped := Fingerprint(tree, p, nil, linked, userName)
+	if bumped == base {
+		t.Error("fingerprint ignores the version of a linked page outside the tree")
+	}
+	name = "Ada Lovelace"
+	if renamed := Fingerprint(tree, p, nil, linked, userName); renamed == bumped {
+		t.Error("fingerprint ignores the resolved author name")
+	}
+}
+
+func TestEscapeTemplates(t *testing.T) {
+	tests := []struct {
+		body      string
+		generator string
+		want      string
+	}{
+		{"plain {text}", Jekyll, "plain {text}"},
+		{"use {{ page.title }}", Jekyll, "{% raw %}use {{ page.title }}{% endraw %}"},
+		{"{% if x %}", Jekyll, "{% raw %}{% if x %}{% endraw %}"},
+		{"{{ a }} {%- endraw -%}", Jekyll, `{% raw %}{{ a }} {% endraw %}{{ "{%- endraw -%}" }}{% raw %}{% endraw %}`},
+		{"{{< figure src=x >}}", Jekyll, "{% raw %}{{< figure src=x >}}{% endraw %}"},
+		{"plain {{ .Title }}", Hugo, "plain {{ .Title }}"},
+		{"{{< figure src=x >}} and {{% note %}}", Hugo, "{{</* figure src=x */>}} and {{%/* note */%}}"},
+		{"{{< a\nb >}}", Hugo, "{{</* a\nb */>}}"},
+	}
+	for _, tt := range tests {
+		if got := EscapeTemplates(tt.body, tt.generator); got != tt.want {
+			t.Errorf("EscapeTemplates(%q, %s) = %q, want %q", tt.body, tt.generator, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is what a watch persists between runs: the records seen by the
+// last poll and the events that have not been delivered yet.
+type State struct {
+	RootID  string                    `json:"root_id"`
+	SavedAt string                    `json:"saved_at,omitempty"`
+	Records map[string]map[string]any `json:"records"`
+	Schemas map[string]map[string]any `json:"schemas,omitempty"`
+	Pending []Event                   `json:"pending"`
+}
+
+// LoadState reads a state file. A missing file is not an error; ok is false
+// then.
+func LoadState(path string) (State, bool, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return State{}, false, nil
+	}
+	if err != nil {
+		return State{}, false, fmt.Errorf("read state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(b, &s); err != nil {
+		return State{}, false, fmt.Errorf("parse state %s: %w", path, err)
+	}
+	return s, true, nil
+}
+
+// SaveState atomically rewrites the state file.
+func SaveState(path string, s State) error {
+	s.SavedAt = time.Now().UTC().Format(time.RFC3339)
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("create state dir: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit state: %w", err)
+	}
+	return nil
+}
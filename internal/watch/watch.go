@@ -0,0 +1,511 @@
+// Package watch polls a page or database and reports changes to its blocks
+// and rows as events.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jodok/nocli/internal/notionclient"
+)
+
+// Event names.
+const (
+	BlockCreated  = "block.created"
+	BlockUpdated  = "block.updated"
+	BlockArchived = "block.archived"
+	// BlockRemoved is a block that is still alive but no longer part of the
+	// watched page (moved elsewhere).
+	BlockRemoved       = "block.removed"
+	RowCreated         = "row.created"
+	RowUpdated         = "row.updated"
+	RowArchived        = "row.archived"
+	RowRemoved         = "row.removed"
+	RowPropertyChanged = "row.property_changed"
+)
+
+// Event is one detected change.
+type Event struct {
+	Event string `json:"event"`
+	// EventID is unique per event; webhook receivers can use it to drop
+	// redeliveries.
+	EventID string `json:"event_id"`
+	// Time is when the change was detected.
+	Time   string `json:"time"`
+	RootID string `json:"root_id"`
+	ID     string `json:"id"`
+	Type   string `json:"type,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// ParentID is the block, or the collection for rows, the record is in.
+	ParentID   string `json:"parent_id,omitempty"`
+	Version    int64  `json:"version,omitempty"`
+	EditedTime string `json:"edited_time,omitempty"`
+	EditedBy   string `json:"edited_by,omitempty"`
+
+	// Fields lists the changed record fields of block.updated and
+	// row.updated.
+	Fields []string `json:"fields,omitempty"`
+
+	// Property, PropertyID and the Before/After values are set for
+	// row.property_changed; Before and After also carry the old and new
+	// title of block.updated.
+	Property    string `json:"property,omitempty"`
+	PropertyID  string `json:"property_id,omitempty"`
+	Before      string `json:"before,omitempty"`
+	After       string `json:"after,omitempty"`
+	BeforeValue any    `json:"before_value,omitempty"`
+	AfterValue  any    `json:"after_value,omitempty"`
+}
+
+// ignoredFields change on every edit and are not reported on their own.
+var ignoredFields = map[string]bool{
+	"version":              true,
+	"last_edited_time":     true,
+	"last_edited_by_id":    true,
+	"last_edited_by_table": true,
+}
+
+// Watcher keeps the last seen state of a page's blocks, or a database's
+// rows, between polls.
+type Watcher struct {
+	client *notionclient.Client
+	rootID string
+	// RowLimit caps rows per database query (default 1000).
+	RowLimit int
+
+	started bool
+	records map[string]map[string]any
+	schemas map[string]map[string]any
+	// collectionVersions and views are only kept in memory; after a
+	// restore they are fetched once again.
+	collectionVersions map[string]int64
+	views              map[string]map[string]any
+}
+
+func New(client *notionclient.Client, rootID string) *Watcher {
+	return &Watcher{
+		client:  client,
+		rootID:  rootID,
+		records: map[string]map[string]any{},
+		schemas: map[string]map[string]any{},
+
+		collectionVersions: map[string]int64{},
+		views:              map[string]map[string]any{},
+	}
+}
+
+// Tracked returns how many records the watcher follows.
+func (w *Watcher) Tracked() int {
+	return len(w.records)
+}
+
+// State returns the baseline the next Poll compares against, for SaveState.
+func (w *Watcher) State() State {
+	return State{RootID: w.rootID, Records: w.records, Schemas: w.schemas, Pending: []Event{}}
+}
+
+// Restore continues from a saved baseline instead of the first poll; the
+// next Poll reports everything that changed since the state was saved.
+func (w *Watcher) Restore(s State) error {
+	if s.RootID != w.rootID {
+		return fmt.Errorf("state is for %s, not %s", s.RootID, w.rootID)
+	}
+	if s.Records != nil {
+		w.records = s.Records
+	}
+	if s.Schemas != nil {
+		w.schemas = s.Schemas
+	}
+	w.started = true
+	return nil
+}
+
+// Poll fetches the current state and returns the changes since the previous
+// poll. The first poll records the baseline and returns no events.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	next, changed, err := w.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !w.started {
+		w.started = true
+		w.records = next
+		return nil, nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	events, gone := w.compare(now, next)
+
+	// Blocks that left the page were usually archived or moved; their
+	// current value says which. Changed ones were synced already.
+	unknown := make([]string, 0, len(gone))
+	for _, id := range gone {
+		if _, ok := changed[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	current, err := w.client.GetRecords(ctx, "block", unknown)
+	if err != nil {
+		return nil, fmt.Errorf("fetch removed blocks: %w", err)
+	}
+	for id, v := range changed {
+		current[id] = v
+	}
+	for _, id := range gone {
+		before := w.records[id]
+		cur := current[id]
+		ev := w.newEvent(now, removed(before), id, before)
+		if cur["id"] != nil {
+			ev.Version = version(cur)
+		}
+		// Records the user cannot read any more come back as {"role": "none"}.
+		if cur["id"] == nil || !notionclient.IsAlive(cur) {
+			ev.Event = archived(before)
+		} else {
+			ev.ParentID, _ = cur["parent_id"].(string)
+		}
+		events = append(events, ev)
+	}
+
+	for i := range events {
+		if events[i].EventID, err = notionclient.NewID(); err != nil {
+			return nil, err
+		}
+	}
+	w.records = next
+	return events, nil
+}
+
+// fetch returns the live records that make up the watched object: the root,
+// its content outside of subpages, database rows, and the subpages
+// themselves. Tracked blocks are re-synced by version, so only the ones that
+// changed are downloaded; blocks new to the content are fetched in full and
+// rows come from a query of each database. Subpages and rows are not
+// expanded. changed holds the re-synced blocks whose version moved.
+func (w *Watcher) fetch(ctx context.Context) (next map[string]map[string]any, changed map[string]map[string]any, err error) {
+	versions := map[string]int64{w.rootID: -1}
+	for id, r := range w.records {
+		if !isRow(r) {
+			versions[id] = version(r)
+		}
+	}
+	changed, err = w.client.ChangedRecords(ctx, "block", versions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch %s: %w", w.rootID, err)
+	}
+	blocks := map[string]map[string]any{}
+	for id, r := range w.records {
+		blocks[id] = r
+	}
+	for id, r := range changed {
+		blocks[id] = r
+	}
+	if root := blocks[w.rootID]; root["id"] == nil {
+		return nil, nil, fmt.Errorf("block %s not found or not accessible", w.rootID)
+	}
+
+	next = map[string]map[string]any{}
+	collections := make([]string, 0)
+	seen := map[string]bool{w.rootID: true}
+	level := []string{w.rootID}
+	for len(level) > 0 {
+		unknown := make([]string, 0)
+		for _, id := range level {
+			if _, ok := blocks[id]; !ok {
+				unknown = append(unknown, id)
+			}
+		}
+		fetched, err := w.client.GetRecords(ctx, "block", unknown)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fetch new blocks: %w", err)
+		}
+		for id, r := range fetched {
+			blocks[id] = r
+		}
+
+		nextLevel := make([]string, 0)
+		for _, id := range level {
+			block := blocks[id]
+			if block["id"] == nil || !notionclient.IsAlive(block) {
+				continue
+			}
+			next[id] = block
+			if id != w.rootID && notionclient.IsPageBlock(block) {
+				continue
+			}
+			for _, child := range notionclient.ContentIDs(block) {
+				if !seen[child] {
+					seen[child] = true
+					nextLevel = append(nextLevel, child)
+				}
+			}
+			cid, err := w.rows(ctx, block, next)
+			if err != nil {
+				return nil, nil, err
+			}
+			if cid != "" {
+				collections = append(collections, cid)
+			}
+		}
+		level = nextLevel
+	}
+
+	if err := w.syncSchemas(ctx, collections); err != nil {
+		return nil, nil, err
+	}
+	return next, changed, nil
+}
+
+// rows adds the live rows of a database block to next and returns its
+// collection ID ("" for other blocks).
+func (w *Watcher) rows(ctx context.Context, block map[string]any, next map[string]map[string]any) (string, error) {
+	viewIDs := notionclient.StringList(block["view_ids"])
+	if len(viewIDs) == 0 {
+		return "", nil
+	}
+	if _, ok := block["collection_id"].(string); !ok {
+		// Linked databases name their collection in the view only.
+		unknown := make([]string, 0)
+		for _, id := range viewIDs {
+			if _, ok := w.views[id]; !ok {
+				unknown = append(unknown, id)
+			}
+		}
+		views, err := w.client.GetRecords(ctx, "collection_view", unknown)
+		if err != nil {
+			return "", fmt.Errorf("fetch database views: %w", err)
+		}
+		for id, v := range views {
+			w.views[id] = v
+		}
+	}
+	cid := notionclient.CollectionIDForBlock(block, w.views)
+	if cid == "" {
+		return "", nil
+	}
+	resp, err := w.client.QueryCollection(ctx, cid, viewIDs[0], w.RowLimit)
+	if err != nil {
+		return "", fmt.Errorf("query collection %s: %w", cid, err)
+	}
+	rows := notionclient.FlattenRecordMap(resp)["block"]
+	for _, id := range notionclient.CollectionRowIDs(resp) {
+		if row := rows[id]; row["id"] != nil && notionclient.IsAlive(row) {
+			next[id] = row
+		}
+	}
+	return cid, nil
+}
+
+// syncSchemas refreshes the schemas of the watched collections when their
+// version moved.
+func (w *Watcher) syncSchemas(ctx context.Context, collectionIDs []string) error {
+	versions := map[string]int64{}
+	for _, id := range collectionIDs {
+		v, ok := w.collectionVersions[id]
+		if !ok {
+			v = -1
+		}
+		versions[id] = v
+	}
+	changed, err := w.client.ChangedRecords(ctx, "collection", versions)
+	if err != nil {
+		return err
+	}
+	for id, c := range changed {
+		w.collectionVersions[id] = version(c)
+		if schema, ok := c["schema"].(map[string]any); ok {
+			w.schemas[id] = schema
+		}
+	}
+	return nil
+}
+
+// compare returns the created and updated events for next against the last
+// seen records, and the IDs of tracked records missing from next.
+func (w *Watcher) compare(now string, next map[string]map[string]any) ([]Event, []string) {
+	events := make([]Event, 0)
+	for _, id := range notionclient.SortedKeys(next) {
+		after := next[id]
+		before, ok := w.records[id]
+		if !ok {
+			events = append(events, w.newEvent(now, created(after), id, after))
+			continue
+		}
+		if version(before) != version(after) {
+			events = append(events, w.changes(now, id, before, after)...)
+		}
+	}
+
+	gone := make([]string, 0)
+	for _, id := range notionclient.SortedKeys(w.records) {
+		if _, ok := next[id]; !ok {
+			gone = append(gone, id)
+		}
+	}
+	return events, gone
+}
+
+func (w *Watcher) newEvent(now string, name string, id string, record map[string]any) Event {
+	typ, _ := record["type"].(string)
+	parent, _ := record["parent_id"].(string)
+	editedBy, _ := record["last_edited_by_id"].(string)
+	return Event{
+		Event:      name,
+		Time:       now,
+		RootID:     w.rootID,
+		ID:         id,
+		Type:       typ,
+		Title:      notionclient.BlockTitle(record),
+		ParentID:   parent,
+		Version:    version(record),
+		EditedTime: notionclient.MillisToISO8601(record["last_edited_time"]),
+		EditedBy:   editedBy,
+	}
+}
+
+// changes compares two versions of a record. Row properties are reported
+// one event each; everything else as a single update listing the fields.
+func (w *Watcher) changes(now string, id string, before map[string]any, after map[string]any) []Event {
+	out := make([]Event, 0)
+	row := isRow(after)
+	fields := make([]string, 0)
+	for _, key := range unionKeys(before, after) {
+		if ignoredFields[key] || reflect.DeepEqual(before[key], after[key]) {
+			continue
+		}
+		if key == "properties" && row {
+			out = append(out, w.propertyChanges(now, id, before, after)...)
+			continue
+		}
+		fields = append(fields, key)
+	}
+	if len(fields) == 0 {
+		return out
+	}
+
+	ev := w.newEvent(now, BlockUpdated, id, after)
+	if row {
+		ev.Event = RowUpdated
+	}
+	ev.Fields = fields
+	if t0, t1 := notionclient.BlockTitle(before), notionclient.BlockTitle(after); t0 != t1 {
+		ev.Before, ev.After = t0, t1
+	}
+	return append([]Event{ev}, out...)
+}
+
+func (w *Watcher) propertyChanges(now string, id string, before map[string]any, after map[string]any) []Event {
+	p0, _ := before["properties"].(map[string]any)
+	p1, _ := after["properties"].(map[string]any)
+	collectionID, _ := after["parent_id"].(string)
+	schema := w.schemas[collectionID]
+
+	out := make([]Event, 0)
+	for _, key := range unionKeys(p0, p1) {
+		if reflect.DeepEqual(p0[key], p1[key]) {
+			continue
+		}
+		ev := w.newEvent(now, RowPropertyChanged, id, after)
+		ev.PropertyID = key
+		ev.Property = key
+		if prop, _ := schema[key].(map[string]any); prop != nil {
+			if name, _ := prop["name"].(string); name != "" {
+				ev.Property = name
+			}
+		}
+		ev.Before, ev.After = PropertyText(p0[key]), PropertyText(p1[key])
+		ev.BeforeValue, ev.AfterValue = p0[key], p1[key]
+		out = append(out, ev)
+	}
+	return out
+}
+
+// PropertyText renders a property value as plain text, with page and user
+// mentions replaced by their IDs and dates by their start (and end) date.
+func PropertyText(v any) string {
+	segments, _ := v.([]any)
+	var b strings.Builder
+	for _, seg := range segments {
+		parts, _ := seg.([]any)
+		if len(parts) == 0 {
+			continue
+		}
+		text, _ := parts[0].(string)
+		if len(parts) > 1 {
+			text = decoratedText(text, parts[1])
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func decoratedText(text string, decorations any) string {
+	decs, _ := decorations.([]any)
+	for _, d := range decs {
+		parts, _ := d.([]any)
+		if len(parts) < 2 {
+			continue
+		}
+		switch tag, _ := parts[0].(string); tag {
+		case "p", "u":
+			if id, _ := parts[1].(string); id != "" {
+				return id
+			}
+		case "d":
+			date, _ := parts[1].(map[string]any)
+			start, _ := date["start_date"].(string)
+			if end, _ := date["end_date"].(string); end != "" {
+				return start + "/" + end
+			}
+			if start != "" {
+				return start
+			}
+		}
+	}
+	return text
+}
+
+func isRow(record map[string]any) bool {
+	pt, _ := record["parent_table"].(string)
+	return pt == "collection"
+}
+
+func created(record map[string]any) string {
+	if isRow(record) {
+		return RowCreated
+	}
+	return BlockCreated
+}
+
+func archived(record map[string]any) string {
+	if isRow(record) {
+		return RowArchived
+	}
+	return BlockArchived
+}
+
+func removed(record map[string]any) string {
+	if isRow(record) {
+		return RowRemoved
+	}
+	return BlockRemoved
+}
+
+func version(record map[string]any) int64 {
+	v, _ := record["version"].(float64)
+	return int64(v)
+}
+
+func unionKeys(a map[string]any, b map[string]any) []string {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return notionclient.SortedKeys(keys)
+}
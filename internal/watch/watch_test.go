@@ -0,0 +1,93 @@
+package watch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func record(version float64, fields map[string]any) map[string]any {
+	r := map[string]any{"version": version, "alive": true}
+	for k, v := range fields {
+		r[k] = v
+	}
+	return r
+}
+
+func TestCompare(t *testing.T) {
+	w := New(nil, "root")
+	w.schemas["coll"] = map[string]any{"st": map[string]any{"name": "Status", "type": "select"}}
+	w.records = map[string]map[string]any{
+		"root": record(1, map[string]any{"type": "page", "content": []any{"a", "b"}}),
+		"a":    record(1, map[string]any{"type": "text", "properties": map[string]any{"title": []any{[]any{"old"}}}}),
+		"b":    record(1, map[string]any{"type": "text"}),
+		"row": record(1, map[string]any{
+			"type": "page", "parent_table": "collection", "parent_id": "coll",
+			"properties": map[string]any{"title": []any{[]any{"Task"}}, "st": []any{[]any{"Open"}}},
+		}),
+	}
+	next := map[string]map[string]any{
+		// Only bookkeeping fields changed: no event.
+		"root": record(2, map[string]any{"type": "page", "content": []any{"a", "b"}, "last_edited_time": float64(1)}),
+		"a":    record(2, map[string]any{"type": "text", "properties": map[string]any{"title": []any{[]any{"new"}}}}),
+		"c":    record(1, map[string]any{"type": "text"}),
+		"row": record(3, map[string]any{
+			"type": "page", "parent_table": "collection", "parent_id": "coll",
+			"properties": map[string]any{"title": []any{[]any{"Task"}}, "st": []any{[]any{"Done"}}},
+		}),
+	}
+
+	events, gone := w.compare("now", next)
+	if !reflect.DeepEqual(gone, []string{"b"}) {
+		t.Errorf("gone = %q, want [b]", gone)
+	}
+	type summary struct{ Event, ID, Property, Before, After string }
+	got := make([]summary, 0, len(events))
+	for _, ev := range events {
+		got = append(got, summary{ev.Event, ev.ID, ev.Property, ev.Before, ev.After})
+	}
+	want := []summary{
+		{BlockUpdated, "a", "", "old", "new"},
+		{BlockCreated, "c", "", "", ""},
+		{RowPropertyChanged, "row", "Status", "Open", "Done"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events =\n%+v\nwant\n%+v", got, want)
+	}
+	if events[0].RootID != "root" || !reflect.DeepEqual(events[0].Fields, []string{"properties"}) {
+		t.Errorf("block.updated = %+v, want root_id root and fields [properties]", events[0])
+	}
+	if events[2].PropertyID != "st" || events[2].Version != 3 {
+		t.Errorf("row.property_changed = %+v, want property_id st and version 3", events[2])
+	}
+}
+
+func TestChangesRowFields(t *testing.T) {
+	w := New(nil, "root")
+	before := record(1, map[string]any{"parent_table": "collection", "parent_id": "coll", "format": map[string]any{"page_icon": "a"}})
+	after := record(2, map[string]any{"parent_table": "collection", "parent_id": "coll", "format": map[string]any{"page_icon": "b"}})
+	events := w.changes("now", "row", before, after)
+	if len(events) != 1 || events[0].Event != RowUpdated || !reflect.DeepEqual(events[0].Fields, []string{"format"}) {
+		t.Errorf("changes = %+v, want one row.updated of format", events)
+	}
+}
+
+func TestPropertyText(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"plain", []any{[]any{"a"}, []any{"b"}}, "ab"},
+		{"bold", []any{[]any{"x", []any{[]any{"b"}}}}, "x"},
+		{"page mention", []any{[]any{"‣", []any{[]any{"p", "page-id"}}}}, "page-id"},
+		{"user mention", []any{[]any{"‣", []any{[]any{"u", "user-id"}}}}, "user-id"},
+		{"date", []any{[]any{"‣", []any{[]any{"d", map[string]any{"start_date": "2024-01-02"}}}}}, "2024-01-02"},
+		{"date range", []any{[]any{"‣", []any{[]any{"d", map[string]any{"start_date": "2024-01-02", "end_date": "2024-01-05"}}}}}, "2024-01-02/2024-01-05"},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := PropertyText(tt.v); got != tt.want {
+			t.Errorf("%s: PropertyText = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
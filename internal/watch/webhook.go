@@ -0,0 +1,152 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Webhook delivery headers.
+const (
+	HeaderEvent     = "X-Nocli-Event"
+	HeaderDelivery  = "X-Nocli-Delivery"
+	HeaderTimestamp = "X-Nocli-Timestamp"
+	// HeaderSignature is "sha256=" followed by the hex HMAC-SHA256 of
+	// "<timestamp>.<body>" keyed with the webhook secret.
+	HeaderSignature = "X-Nocli-Signature"
+)
+
+// maxWebhookDelay caps the backoff between delivery attempts.
+const maxWebhookDelay = 30 * time.Second
+
+// Webhook POSTs events as JSON to a URL.
+type Webhook struct {
+	URL    string
+	Secret string
+	// Retries is how many times Deliver retries a retryable failure before
+	// returning it.
+	Retries int
+	Client  *http.Client
+}
+
+// DeliveryError is returned by Deliver when an event could not be posted.
+type DeliveryError struct {
+	EventID  string
+	Attempts int
+	// Retryable is set for network errors and 408, 429 and 5xx responses,
+	// which a later delivery may get past.
+	Retryable bool
+	Err       error
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("deliver %s after %d attempt(s): %v", e.EventID, e.Attempts, e.Err)
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Deliver posts one event, retrying network errors and 408, 429 and 5xx
+// responses with exponential backoff. Other 4xx responses fail immediately.
+// Failures are returned as *DeliveryError.
+func (h *Webhook) Deliver(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	delay := time.Second
+	for attempt := 0; ; attempt++ {
+		retry, err := h.post(ctx, client, ev, body)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retry || attempt >= h.Retries {
+			return &DeliveryError{EventID: ev.EventID, Attempts: attempt + 1, Retryable: retry, Err: err}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxWebhookDelay)
+	}
+}
+
+func (h *Webhook) post(ctx context.Context, client *http.Client, ev Event, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nocli-webhook")
+	req.Header.Set(HeaderEvent, ev.Event)
+	req.Header.Set(HeaderDelivery, ev.EventID)
+	req.Header.Set(HeaderTimestamp, ts)
+	if h.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(h.Secret, ts, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return false, fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+// Sign returns the HeaderSignature value for a body sent at timestamp ts.
+func Sign(secret string, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// AppendDeadLetter records an event that could not be delivered as one JSON
+// line in path.
+func AppendDeadLetter(path string, ev Event, deliveryErr error) error {
+	line, err := json.Marshal(map[string]any{
+		"failed_at": time.Now().UTC().Format(time.RFC3339),
+		"error":     deliveryErr.Error(),
+		"event":     ev,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal dead letter: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open dead-letter file: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write dead-letter file: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,114 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// printf '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	if got := Sign("secret", "1700000000", []byte(`{"a":1}`)); got != want {
+		t.Errorf("Sign = %q, want %q", got, want)
+	}
+}
+
+func TestDeliverSignsRequest(t *testing.T) {
+	ev := Event{Event: BlockUpdated, EventID: "ev-1", ID: "block"}
+	var gotErr error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts := r.Header.Get(HeaderTimestamp)
+		var got Event
+		switch {
+		case r.Header.Get(HeaderSignature) != Sign("secret", ts, body):
+			gotErr = errors.New("signature mismatch")
+		case r.Header.Get(HeaderEvent) != BlockUpdated || r.Header.Get(HeaderDelivery) != "ev-1":
+			gotErr = errors.New("wrong event headers")
+		case json.Unmarshal(body, &got) != nil || got.ID != "block":
+			gotErr = errors.New("wrong body")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	h := &Webhook{URL: srv.URL, Secret: "secret"}
+	if err := h.Deliver(context.Background(), ev); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if gotErr != nil {
+		t.Error(gotErr)
+	}
+}
+
+func TestDeliverErrors(t *testing.T) {
+	tests := []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusGone, false},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusBadGateway, true},
+	}
+	for _, tt := range tests {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(tt.status)
+		}))
+		err := (&Webhook{URL: srv.URL, Secret: "s"}).Deliver(context.Background(), Event{EventID: "ev"})
+		srv.Close()
+
+		var deliveryErr *DeliveryError
+		if !errors.As(err, &deliveryErr) {
+			t.Errorf("status %d: error = %v, want *DeliveryError", tt.status, err)
+			continue
+		}
+		if deliveryErr.Retryable != tt.retryable || deliveryErr.Attempts != 1 || calls != 1 {
+			t.Errorf("status %d: retryable=%v attempts=%d calls=%d, want retryable=%v after one call",
+				tt.status, deliveryErr.Retryable, deliveryErr.Attempts, calls, tt.retryable)
+		}
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	err := (&Webhook{URL: srv.URL}).Deliver(context.Background(), Event{EventID: "ev"})
+	var deliveryErr *DeliveryError
+	if !errors.As(err, &deliveryErr) || !deliveryErr.Retryable {
+		t.Errorf("unreachable webhook: error = %v, want a retryable *DeliveryError", err)
+	}
+}
+
+func TestAppendDeadLetter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.ndjson")
+	for _, id := range []string{"a", "b"} {
+		if err := AppendDeadLetter(path, Event{EventID: id}, errors.New("webhook returned 400")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var entry struct {
+		Error string `json:"error"`
+		Event Event  `json:"event"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil || entry.Event.EventID != "b" || entry.Error != "webhook returned 400" {
+		t.Errorf("second line = %s (%v)", lines[1], err)
+	}
+}